@@ -0,0 +1,258 @@
+package simpleapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditPurge   AuditAction = "purge"
+	AuditRestore AuditAction = "restore"
+)
+
+// single change of an audited entity
+type AuditRecord struct {
+	Id uint64
+
+	EntityType string `gorm:"index:idx_audit_entity;size:255"`
+	EntityId   string `gorm:"index:idx_audit_entity;size:64"`
+
+	ActorId string      `gorm:"size:64"`
+	Action  AuditAction `gorm:"size:16"`
+
+	// json object of changed fields: out name -> {"old": .., "new": ..}
+	Diff string
+
+	CreatedAt time.Time
+}
+
+func (AuditRecord) TableName() string {
+	return "simpleapi_audit"
+}
+
+type auditChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Audit enables change history of entities, every create, update and removal
+// writes a record in the same transaction as the change itself
+func (it *CrudConfig[T, CtxType]) Audit() *CrudConfig[T, CtxType] {
+
+	if it.App.Db.automigrate {
+		err := it.App.Db.Raw().AutoMigrate(&AuditRecord{})
+		if err != nil {
+			panic(fmt.Sprintf("unable to migrate audit table: %s", err.Error()))
+		}
+	}
+
+	it.audit = true
+
+	return it
+}
+
+// values of all outable fields regardless of request permissions
+func (result *CrudConfig[T, CtxType]) auditSnapshot(obj *T) map[string]any {
+
+	snapshot := map[string]any{}
+
+	if obj == nil {
+		return snapshot
+	}
+
+	reflected := reflect.ValueOf(obj).Elem()
+
+	for _, declName := range result.TypeDataModel.Outable {
+
+		fieldInfo := result.TypeDataModel.Fields[declName]
+
+		snapshot[*fieldInfo.Name] = dtoValue(fieldInfo, reflected.FieldByName(declName).Interface())
+	}
+
+	return snapshot
+}
+
+// changed fields between two states, nil prev or cur means object was created or removed
+func (result *CrudConfig[T, CtxType]) auditDiff(prev *T, cur *T) map[string]auditChange {
+
+	prevSnapshot := result.auditSnapshot(prev)
+	curSnapshot := result.auditSnapshot(cur)
+
+	diff := map[string]auditChange{}
+
+	for _, declName := range result.TypeDataModel.Outable {
+
+		name := *result.TypeDataModel.Fields[declName].Name
+
+		prevVal, prevOk := prevSnapshot[name]
+		curVal, curOk := curSnapshot[name]
+
+		prevJson, _ := json.Marshal(prevVal)
+		curJson, _ := json.Marshal(curVal)
+
+		if prevOk && curOk && string(prevJson) == string(curJson) {
+			continue
+		}
+
+		diff[name] = auditChange{
+			Old: prevVal,
+			New: curVal,
+		}
+	}
+
+	return diff
+}
+
+func (result *CrudConfig[T, CtxType]) primaryKeyValue(obj *T) any {
+
+	declName, ok := result.TypeDataModel.DeclaredByColumn(result.primaryIdDbName)
+	if !ok {
+		return nil
+	}
+
+	return reflect.ValueOf(obj).Elem().FieldByName(declName).Interface()
+}
+
+// writes audit record if audit is enabled, should be called within the change transaction
+func (result *CrudConfig[T, CtxType]) writeAudit(appctx *AppContext[CtxType], action AuditAction, prev *T, cur *T, reqData RequestData) error {
+
+	if !result.audit {
+		return nil
+	}
+
+	subject := cur
+	if subject == nil {
+		subject = prev
+	}
+
+	diff, err := json.Marshal(result.auditDiff(prev, cur))
+	if err != nil {
+		return fmt.Errorf("unable to encode audit diff: %s", err.Error())
+	}
+
+	actor := ""
+	if reqData.AuthorizedUserId != nil {
+		actor = fmt.Sprintf("%v", reqData.AuthorizedUserId)
+	}
+
+	record := AuditRecord{
+		EntityType: result.TypeDataModel.TypeName,
+		EntityId:   fmt.Sprintf("%v", result.primaryKeyValue(subject)),
+		ActorId:    actor,
+		Action:     action,
+		Diff:       string(diff),
+	}
+
+	reqData.log_format(" audit %s of %s #%s", action, record.EntityType, record.EntityId)
+
+	// plain insert, audit records have no lifecycle hooks
+	return appctx.Db.Raw().Create(&record).Error
+}
+
+// EntityHistory lists audit records of an object, newest first.
+// fields not readable by request are removed from diffs
+func (result *CrudConfig[T, CtxType]) EntityHistory(appctx *AppContext[CtxType], obj T, page int, reqData RequestData) *RespErr {
+
+	if !result.audit {
+		return NewRespErr(404, HM{
+			"msg": "history is not enabled",
+		})
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+
+	perPage := result.paging.PerPage
+
+	records := []AuditRecord{}
+
+	findErr := appctx.Db.Raw().
+		Where("entity_type = ? AND entity_id = ?", result.TypeDataModel.TypeName, fmt.Sprintf("%v", result.primaryKeyValue(&obj))).
+		Order("id DESC").
+		Limit(perPage + 1).
+		Offset((page - 1) * perPage).
+		Find(&records).Error
+
+	if findErr != nil {
+
+		responseData := HM{
+			"msg": "unable to load history",
+		}
+
+		if reqData.IsAdmin {
+			responseData["err"] = findErr.Error()
+		}
+
+		return NewRespErr(500, responseData)
+	}
+
+	hasMore := len(records) > perPage
+	if hasMore {
+		records = records[:perPage]
+	}
+
+	readable := map[string]bool{}
+	for _, declName := range result.TypeDataModel.Outable {
+		fieldInfo := result.TypeDataModel.Fields[declName]
+		readable[*fieldInfo.Name] = fieldInfo.Readable(reqData)
+	}
+
+	items := []HM{}
+
+	for _, it := range records {
+
+		diff := map[string]auditChange{}
+		json.Unmarshal([]byte(it.Diff), &diff)
+
+		for name := range diff {
+			if !readable[name] {
+				delete(diff, name)
+			}
+		}
+
+		items = append(items, HM{
+			"id":         it.Id,
+			"action":     it.Action,
+			"actor_id":   it.ActorId,
+			"diff":       diff,
+			"created_at": it.CreatedAt.Unix(),
+		})
+	}
+
+	return NewRespErr(200, HM{
+		"items":    items,
+		"has_more": hasMore,
+	})
+}
+
+func (result *CrudConfig[T, CtxType]) generateHistoryEndpoint(existingItems Router) {
+
+	if !result.audit {
+		return
+	}
+
+	existingItems.Handle(http.MethodGet, "/history", func(ctx Request) {
+
+		reqData := result.RequestData(ctx)
+
+		page, _ := strconv.Atoi(ctx.Query("page"))
+
+		historyResp := result.EntityHistory(result.App, MustGetObjectFromContext[T](ctx, "_eobj"), page, reqData)
+
+		if reqData.Debug {
+			historyResp.Data["logs"] = reqData.getDebugLogs()
+		}
+
+		ctx.JSON(historyResp.Httpcode, historyResp.Data)
+	})
+}
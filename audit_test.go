@@ -0,0 +1,103 @@
+package simpleapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAuditDiff(t *testing.T) {
+
+	crud := mockEventsCrud()
+
+	prev := MockEvent{Id: 1, Label: "a"}
+	cur := MockEvent{Id: 1, Label: "b"}
+
+	diff := crud.auditDiff(&prev, &cur)
+
+	if len(diff) != 1 || diff["label"].Old != "a" || diff["label"].New != "b" {
+		t.Errorf("only changed fields should be in diff, got %+v", diff)
+	}
+
+	created := crud.auditDiff(nil, &cur)
+
+	if len(created) != 3 || created["id"].Old != nil || created["id"].New != uint64(1) {
+		t.Errorf("created object should report all fields, got %+v", created)
+	}
+}
+
+func TestAuditHistory(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockEvent{})
+
+	New(group, ServeMuxRouter(mux).Group("/events"), MockEvent{}).
+		Audit().
+		OnAfterCreate(func(appctx *AppContext[MockAppContext], obj *MockEvent) error {
+			if obj.Label == "failing" {
+				return errors.New("rejected")
+			}
+			return nil
+		}).
+		Generate()
+
+	app := group.Ctx
+
+	history := func(headers ...string) []map[string]any {
+
+		resp := mockRequest(t, mux, http.MethodGet, "/events/1/history", "", headers...)
+		if resp.Code != 200 {
+			t.Fatalf("unable to load history: %d %v", resp.Code, resp.Body)
+		}
+
+		result := []map[string]any{}
+		for _, it := range resp.Body["items"].([]any) {
+			result = append(result, it.(map[string]any))
+		}
+		return result
+	}
+
+	resp := mockRequest(t, mux, http.MethodPost, "/events", `{"label": "a"}`, "X-User: 7")
+	if resp.Code != 200 {
+		t.Fatalf("unable to create event: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPatch, "/events/1", `{"label": "b"}`, "X-User: 7")
+	if resp.Code != 200 {
+		t.Fatalf("unable to update event: %d %v", resp.Code, resp.Body)
+	}
+
+	items := history()
+	if len(items) != 2 || items[0]["action"] != string(AuditUpdate) || items[1]["action"] != string(AuditCreate) || items[0]["actor_id"] != "7" {
+		t.Fatalf("unexpected history: %v", items)
+	}
+
+	updated := items[0]["diff"].(map[string]any)
+	if len(updated) != 1 || updated["label"].(map[string]any)["old"] != "a" || updated["label"].(map[string]any)["new"] != "b" {
+		t.Errorf("update diff should have changed fields only: %v", updated)
+	}
+
+	// admin only field is hidden from diffs of regular users
+	if _, leaked := items[1]["diff"].(map[string]any)["_deleted"]; leaked {
+		t.Errorf("admin only field leaked to history: %v", items[1])
+	}
+
+	if _, ok := history("X-Admin: 1")[1]["diff"].(map[string]any)["_deleted"]; !ok {
+		t.Errorf("admin should see all fields in history")
+	}
+
+	resp = mockRequest(t, mux, http.MethodDelete, "/events/1", "")
+	if resp.Code != 200 {
+		t.Fatalf("unable to remove event: %d %v", resp.Code, resp.Body)
+	}
+
+	items = history("X-Admin: 1")
+	if len(items) != 3 || items[0]["action"] != string(AuditDelete) {
+		t.Errorf("removal should be audited: %v", items)
+	}
+
+	// failed change is rolled back together with its audit record
+	resp = mockRequest(t, mux, http.MethodPost, "/events", `{"label": "failing"}`)
+	if resp.Code != 500 || countRows[AuditRecord](t, app) != 3 || countRows[MockEvent](t, app) != 1 {
+		t.Errorf("failed create should leave no audit record: %d %v", resp.Code, resp.Body)
+	}
+}
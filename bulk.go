@@ -0,0 +1,455 @@
+package simpleapi
+
+import (
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+type BulkConfig struct {
+	// objects inserted per INSERT statement
+	BatchSize int
+
+	// max objects accepted in one request
+	MaxItems int
+}
+
+func (it *CrudConfig[T, CtxType]) Bulk(config BulkConfig) *CrudConfig[T, CtxType] {
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = it.bulk.BatchSize
+	}
+
+	if config.MaxItems <= 0 {
+		config.MaxItems = it.bulk.MaxItems
+	}
+
+	it.bulk = config
+
+	return it
+}
+
+// CreateEntities creates all objects in a single transaction, any failure rolls back all of them.
+// in partial mode each object is created separately and failures are reported per item
+func (result *CrudConfig[T, CtxType]) CreateEntities(appctx *AppContext[CtxType], items []gjson.Result, reqData RequestData, partial bool) (objectsCreated []T, respData *RespErr) {
+
+	if len(items) == 0 {
+		respData = NewRespErr(400, HM{
+			"msg": "no objects provided",
+		})
+		return
+	}
+
+	if len(items) > result.bulk.MaxItems {
+		respData = NewRespErr(413, HM{
+			"msg": fmt.Sprintf("too many objects, max %d allowed", result.bulk.MaxItems),
+		})
+		return
+	}
+
+	if partial {
+		return result.createEntitiesPartially(appctx, items, reqData)
+	}
+
+	objects := make([]T, len(items))
+	failedItems := []HM{}
+
+	for idx, it := range items {
+
+		fillError := appctx.FillEntityFromDto(result.TypeDataModel, &objects[idx], it, nil, reqData)
+
+		if fillError != nil {
+			failedItems = append(failedItems, HM{
+				"index": idx,
+				"msg":   "can't fill object with provided data",
+				"err":   fillError.Error(),
+			})
+			continue
+		}
+
+		stampErr := result.stampAuthor(&objects[idx], true, reqData)
+		if stampErr != nil {
+			failedItems = append(failedItems, HM{
+				"index": idx,
+				"msg":   "can't fill object with provided data",
+				"err":   stampErr.Error(),
+			})
+			continue
+		}
+
+		validationErrs := result.ValidateEntity(appctx, &objects[idx], reqData)
+		if len(validationErrs) > 0 {
+			failedItems = append(failedItems, HM{
+				"index":  idx,
+				"msg":    "validation failed",
+				"fields": map[string]string(validationErrs),
+			})
+		}
+	}
+
+	if len(failedItems) > 0 {
+		respData = NewRespErr(422, HM{
+			"msg":   "some objects are invalid, nothing created",
+			"items": failedItems,
+		})
+		return
+	}
+
+	failedIdx := -1
+
+	createdErr := appctx.DbTransaction(func(isolatedContext AppContext[CtxType]) error {
+
+		crudCtx := CrudContext[T, CtxType]{
+			App:  &isolatedContext,
+			Crud: result,
+		}
+
+		if result.objectCreate != nil {
+			for idx := range objects {
+				errCreate := callHook("OnObjectCreate", func() error { return result.objectCreate(crudCtx, &objects[idx]) })
+				if errCreate != nil {
+					failedIdx = idx
+					return fmt.Errorf("unable to perform pre object create hook: %s", errCreate.Error())
+				}
+			}
+		}
+
+		createErr := isolatedContext.Db.CreateInBatches(&objects, result.bulk.BatchSize)
+		if createErr != nil {
+			return fmt.Errorf("unable to create new objects: %s", createErr.Error())
+		}
+
+		if result.afterCreate != nil {
+			for idx := range objects {
+				afterCreateErr := callHook("OnAfterCreate", func() error { return result.afterCreate(&isolatedContext, &objects[idx]) })
+				if afterCreateErr != nil {
+					failedIdx = idx
+					return fmt.Errorf("unable to perform after object create hook: %s", afterCreateErr.Error())
+				}
+			}
+		}
+
+		for idx := range objects {
+			auditErr := result.recordChange(&isolatedContext, AuditCreate, nil, &objects[idx], reqData)
+			if auditErr != nil {
+				failedIdx = idx
+				return auditErr
+			}
+		}
+
+		return nil
+	})
+
+	if createdErr != nil {
+
+		errData := HM{
+			"msg": "unable to create objects, nothing created",
+			"err": createdErr.Error(),
+		}
+
+		if failedIdx >= 0 {
+			errData["index"] = failedIdx
+		}
+
+		respData = NewRespErr(500, errData)
+		return
+	}
+
+	createdItems := []HM{}
+
+	for idx, it := range objects {
+		createdItems = append(createdItems, HM{
+			"index":   idx,
+			"created": true,
+			"object":  ToDto(it, appctx, reqData).Unwrap(),
+		})
+	}
+
+	return objects, NewRespErr(200, HM{
+		"created": len(objects),
+		"failed":  0,
+		"items":   createdItems,
+	})
+}
+
+func (result *CrudConfig[T, CtxType]) createEntitiesPartially(appctx *AppContext[CtxType], items []gjson.Result, reqData RequestData) (objectsCreated []T, respData *RespErr) {
+
+	resultItems := []HM{}
+	failed := 0
+
+	for idx, it := range items {
+
+		created, createResp := result.CreateEntity(appctx, nil, it, reqData)
+
+		itemResp := HM{
+			"index": idx,
+			"code":  createResp.Httpcode,
+		}
+
+		for k, v := range createResp.Data {
+			itemResp[k] = v
+		}
+
+		if createResp.Httpcode == 200 {
+			objectsCreated = append(objectsCreated, created)
+		} else {
+			failed += 1
+			itemResp["created"] = false
+		}
+
+		resultItems = append(resultItems, itemResp)
+	}
+
+	return objectsCreated, NewRespErr(200, HM{
+		"created": len(objectsCreated),
+		"failed":  failed,
+		"items":   resultItems,
+	})
+}
+
+func (result *CrudConfig[T, CtxType]) handleBulkCreate(ctx Request, parsed gjson.Result) {
+
+	if !parsed.IsArray() {
+		ctx.JSON(400, HM{
+			"msg": "array of objects expected",
+		})
+		return
+	}
+
+	reqData := result.RequestData(ctx)
+
+	partial := ctx.Query("partial") == "1" || ctx.Query("partial") == "true"
+
+	_, bulkResp := result.CreateEntities(result.App, parsed.Array(), reqData, partial)
+
+	if reqData.Debug {
+		bulkResp.Data["logs"] = reqData.getDebugLogs()
+	}
+
+	ctx.JSON(bulkResp.Httpcode, bulkResp.Data)
+}
+
+// body of bulk update and bulk delete requests
+type bulkByFilterRequest struct {
+	Filter  HM
+	Patch   gjson.Result
+	Confirm bool
+}
+
+func parseBulkByFilterRequest(ctx Request) (req bulkByFilterRequest, err error) {
+
+	data, err := ctx.Body()
+	if err != nil {
+		return
+	}
+
+	req.Filter = HM{}
+
+	parsed := gjson.ParseBytes(data)
+
+	filterJson := parsed.Get("filter")
+	if !filterJson.Exists() && ctx.Query("filter") != "" {
+		filterJson = gjson.Parse(ctx.Query("filter"))
+	}
+
+	if filterJson.Exists() {
+		filterMap, ok := filterJson.Value().(map[string]any)
+		if !ok {
+			err = fmt.Errorf("filter should be an object")
+			return
+		}
+		req.Filter = filterMap
+	}
+
+	req.Patch = parsed.Get("patch")
+	req.Confirm = parsed.Get("confirm").Bool() || ctx.Query("confirm") == "1" || ctx.Query("confirm") == "true"
+
+	return
+}
+
+// loads all objects matching list filter, at most bulk MaxItems
+func (result *CrudConfig[T, CtxType]) findByFilter(appctx *AppContext[CtxType], filtersMap HM, reqData RequestData) (items []T, respData *RespErr) {
+
+	filterCompiled := prepareFilterData[T, CtxType](filtersMap, result, result.TypeDataModel, reqData, ListQueryParams{})
+
+	if !filterCompiled.IsOk() {
+		respData = NewRespErr(403, HM{
+			"msg": "no access",
+		})
+		return
+	}
+
+	filterData := filterCompiled.Unwrap()
+
+	items = []T{}
+
+	findErr := result.rawDb(appctx).
+		Table(result.tableName).
+		Where(filterData.QueryPlaceholder, filterData.Args...).
+		Order(orderClause(result.tableName, result.cursorKeys(nil), false)).
+		Limit(result.bulk.MaxItems + 1).
+		Find(&items).Error
+
+	if findErr != nil {
+		respData = NewRespErr(500, HM{
+			"msg": "unable to find objects",
+			"err": findErr.Error(),
+		})
+		return
+	}
+
+	if len(items) > result.bulk.MaxItems {
+		respData = NewRespErr(413, HM{
+			"msg": fmt.Sprintf("filter matches more than %d objects, narrow it down", result.bulk.MaxItems),
+		})
+		return
+	}
+
+	return
+}
+
+func bulkNotConfirmedResp(matched int) *RespErr {
+	return NewRespErr(400, HM{
+		"msg":     "bulk operation should be confirmed with `confirm` flag",
+		"matched": matched,
+	})
+}
+
+// UpdateEntitiesByFilter applies the same patch to every object matching list filter in one transaction.
+// objects go through regular update path, so field permissions, validation and hooks are applied
+func (result *CrudConfig[T, CtxType]) UpdateEntitiesByFilter(appctx *AppContext[CtxType], filtersMap HM, patch gjson.Result, confirm bool, reqData RequestData) *RespErr {
+
+	if !patch.IsObject() {
+		return NewRespErr(400, HM{
+			"msg": "patch object expected",
+		})
+	}
+
+	items, findResp := result.findByFilter(appctx, filtersMap, reqData)
+	if findResp != nil {
+		return findResp
+	}
+
+	if !confirm {
+		return bulkNotConfirmedResp(len(items))
+	}
+
+	var failedResp *RespErr
+	failedIdx := -1
+
+	updateErr := appctx.DbTransaction(func(isolatedContext AppContext[CtxType]) error {
+
+		for idx, it := range items {
+
+			_, updateResp := result.UpdateEntity(&isolatedContext, it, patch, reqData)
+
+			if updateResp.Httpcode != 200 {
+				failedResp = updateResp
+				failedIdx = idx
+
+				return fmt.Errorf("unable to update object #%d", idx)
+			}
+		}
+
+		return nil
+	})
+
+	if updateErr != nil {
+
+		if failedResp != nil {
+			failedResp.Data["index"] = failedIdx
+			failedResp.Data["affected"] = 0
+			return failedResp
+		}
+
+		return NewRespErr(500, HM{
+			"msg":      "unable to update objects",
+			"err":      updateErr.Error(),
+			"affected": 0,
+		})
+	}
+
+	return NewRespErr(200, HM{
+		"affected": len(items),
+	})
+}
+
+// DeleteEntitiesByFilter removes every object matching list filter in one transaction
+func (result *CrudConfig[T, CtxType]) DeleteEntitiesByFilter(appctx *AppContext[CtxType], filtersMap HM, confirm bool, reqData RequestData) *RespErr {
+
+	items, findResp := result.findByFilter(appctx, filtersMap, reqData)
+	if findResp != nil {
+		return findResp
+	}
+
+	if !confirm {
+		return bulkNotConfirmedResp(len(items))
+	}
+
+	var failedResp *RespErr
+	failedIdx := -1
+
+	deleteErr := appctx.DbTransaction(func(isolatedContext AppContext[CtxType]) error {
+
+		for idx, it := range items {
+
+			deleteResp := result.DeleteEntity(&isolatedContext, it, reqData)
+
+			if deleteResp.Httpcode != 200 {
+				failedResp = deleteResp
+				failedIdx = idx
+
+				return fmt.Errorf("unable to delete object #%d", idx)
+			}
+		}
+
+		return nil
+	})
+
+	if deleteErr != nil {
+
+		if failedResp != nil {
+			failedResp.Data["index"] = failedIdx
+			failedResp.Data["affected"] = 0
+			return failedResp
+		}
+
+		return NewRespErr(500, HM{
+			"msg":      "unable to delete objects",
+			"err":      deleteErr.Error(),
+			"affected": 0,
+		})
+	}
+
+	return NewRespErr(200, HM{
+		"affected": len(items),
+	})
+}
+
+func (result *CrudConfig[T, CtxType]) handleBulkByFilter(ctx Request, isDelete bool) {
+
+	reqData := result.RequestData(ctx)
+
+	bulkReq, err := parseBulkByFilterRequest(ctx)
+	if err != nil {
+		ctx.JSON(400, HM{
+			"msg": "unable to parse bulk request",
+			"err": err.Error(),
+		})
+		return
+	}
+
+	var bulkResp *RespErr
+
+	if isDelete {
+		bulkResp = result.DeleteEntitiesByFilter(result.App, bulkReq.Filter, bulkReq.Confirm, reqData)
+	} else {
+		bulkResp = result.UpdateEntitiesByFilter(result.App, bulkReq.Filter, bulkReq.Patch, bulkReq.Confirm, reqData)
+	}
+
+	if reqData.Debug {
+		bulkResp.Data["logs"] = reqData.getDebugLogs()
+	}
+
+	ctx.JSON(bulkResp.Httpcode, bulkResp.Data)
+}
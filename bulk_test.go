@@ -0,0 +1,185 @@
+package simpleapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func countRows[T any](t *testing.T, app AppContext[MockAppContext]) int64 {
+
+	cnt := int64(0)
+
+	if err := app.Db.Raw().Model(new(T)).Count(&cnt).Error; err != nil {
+		t.Fatalf("unable to count rows: %s", err.Error())
+	}
+
+	return cnt
+}
+
+func TestBulkCreate(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockAccount{})
+
+	New(group, ServeMuxRouter(mux).Group("/accounts"), MockAccount{}).
+		OnAfterCreate(func(appctx *AppContext[MockAppContext], obj *MockAccount) error {
+			if obj.Nickname == "failing" {
+				return errors.New("rejected")
+			}
+			return nil
+		}).
+		Generate()
+
+	// invalid item, nothing is stored
+	resp := mockRequest(t, mux, http.MethodPost, "/accounts/bulk", `[
+		{"email": "a@test.com", "nick": "alpha", "age": 20},
+		{"email": "bad", "nick": "beta", "age": 20}
+	]`)
+
+	if resp.Code != 422 || countRows[MockAccount](t, group.Ctx) != 0 {
+		t.Errorf("invalid item should reject the whole batch: %d %v", resp.Code, resp.Body)
+	}
+
+	// hook fails after insert, whole transaction is rolled back
+	resp = mockRequest(t, mux, http.MethodPost, "/accounts/bulk", `[
+		{"email": "a@test.com", "nick": "alpha", "age": 20},
+		{"email": "b@test.com", "nick": "failing", "age": 20}
+	]`)
+
+	if resp.Code != 500 || resp.Body["index"] != float64(1) || countRows[MockAccount](t, group.Ctx) != 0 {
+		t.Errorf("failed hook should roll back the batch: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPost, "/accounts/bulk", `[
+		{"email": "a@test.com", "nick": "alpha", "age": 20},
+		{"email": "b@test.com", "nick": "beta", "age": 20}
+	]`)
+
+	if resp.Code != 200 || resp.Body["created"] != float64(2) || countRows[MockAccount](t, group.Ctx) != 2 {
+		t.Errorf("valid batch is not created: %d %v", resp.Code, resp.Body)
+	}
+}
+
+func TestBulkCreatePartial(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockAccount{})
+
+	New(group, ServeMuxRouter(mux).Group("/accounts"), MockAccount{}).
+		OnAfterCreate(func(appctx *AppContext[MockAppContext], obj *MockAccount) error {
+			if obj.Nickname == "failing" {
+				return errors.New("rejected")
+			}
+			return nil
+		}).
+		Generate()
+
+	resp := mockRequest(t, mux, http.MethodPost, "/accounts/bulk?partial=true", `[
+		{"email": "a@test.com", "nick": "alpha", "age": 20},
+		{"email": "bad", "nick": "beta", "age": 20},
+		{"email": "c@test.com", "nick": "failing", "age": 20}
+	]`)
+
+	if resp.Code != 200 || resp.Body["created"] != float64(1) || resp.Body["failed"] != float64(2) {
+		t.Fatalf("unexpected partial response: %d %v", resp.Code, resp.Body)
+	}
+
+	items := resp.Body["items"].([]any)
+
+	expected := []struct {
+		code    float64
+		created bool
+	}{
+		{200, true},
+		{422, false},
+		{500, false},
+	}
+
+	for idx, it := range expected {
+
+		item := items[idx].(map[string]any)
+
+		if item["index"] != float64(idx) || item["code"] != it.code || item["created"] != it.created {
+			t.Errorf("unexpected result of item %d: %v", idx, item)
+		}
+	}
+
+	if countRows[MockAccount](t, group.Ctx) != 1 {
+		t.Errorf("only valid item should be stored")
+	}
+}
+
+func TestBulkByFilter(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockAccount{})
+
+	New(group, ServeMuxRouter(mux).Group("/accounts"), MockAccount{}).
+		Bulk(BulkConfig{MaxItems: 3}).
+		OnBeforeDelete(func(crudContext CrudContext[MockAccount, MockAppContext], obj *MockAccount) error {
+			if obj.Nickname == "keeper" {
+				return errors.New("rejected")
+			}
+			return nil
+		}).
+		Generate()
+
+	app := group.Ctx
+
+	for _, it := range []MockAccount{
+		{Email: "a@test.com", Nickname: "alpha", Age: 20, Plan: "free"},
+		{Email: "invalid", Nickname: "beta", Age: 20, Plan: "free"},
+		{Email: "c@test.com", Nickname: "gamma", Age: 20, Plan: "pro"},
+		{Email: "d@test.com", Nickname: "keeper", Age: 20, Plan: "pro"},
+	} {
+		if err := app.Db.Create(&it); err != nil {
+			t.Fatalf("unable to create account: %s", err.Error())
+		}
+	}
+
+	ages := func() (result []int) {
+		items := []MockAccount{}
+		app.Db.Raw().Order("id").Find(&items)
+		for _, it := range items {
+			result = append(result, it.Age)
+		}
+		return
+	}
+
+	// not confirmed, matched objects are reported only
+	resp := mockRequest(t, mux, http.MethodPatch, "/accounts", `{"filter": {"plan": "pro"}, "patch": {"age": 40}}`)
+	if resp.Code != 400 || resp.Body["matched"] != float64(2) || ages()[2] != 20 {
+		t.Errorf("bulk update should require confirmation: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPatch, "/accounts", `{"filter": {"plan": "pro"}, "patch": {"age": 40}, "confirm": true}`)
+	if resp.Code != 200 || resp.Body["affected"] != float64(2) || ages()[2] != 40 || ages()[3] != 40 {
+		t.Errorf("confirmed bulk update failed: %d %v", resp.Code, resp.Body)
+	}
+
+	// second object fails validation, first one is rolled back too
+	resp = mockRequest(t, mux, http.MethodPatch, "/accounts", `{"filter": {"plan": "free"}, "patch": {"age": 50}, "confirm": true}`)
+	if resp.Code != 422 || resp.Body["index"] != float64(1) || resp.Body["affected"] != float64(0) || ages()[0] != 20 {
+		t.Errorf("failed bulk update should be rolled back: %d %v %v", resp.Code, resp.Body, ages())
+	}
+
+	// matches more than MaxItems
+	resp = mockRequest(t, mux, http.MethodDelete, "/accounts", `{"confirm": true}`)
+	if resp.Code != 413 || countRows[MockAccount](t, app) != 4 {
+		t.Errorf("filter matching over MaxItems should be rejected: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodDelete, "/accounts", `{"filter": {"plan": "pro"}}`)
+	if resp.Code != 400 || resp.Body["matched"] != float64(2) || countRows[MockAccount](t, app) != 4 {
+		t.Errorf("bulk delete should require confirmation: %d %v", resp.Code, resp.Body)
+	}
+
+	// hook vetoes second object, first one is restored
+	resp = mockRequest(t, mux, http.MethodDelete, "/accounts", `{"filter": {"plan": "pro"}, "confirm": true}`)
+	if resp.Code != 409 || resp.Body["index"] != float64(1) || countRows[MockAccount](t, app) != 4 {
+		t.Errorf("failed bulk delete should be rolled back: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodDelete, "/accounts", `{"filter": {"plan": "free"}, "confirm": true}`)
+	if resp.Code != 200 || resp.Body["affected"] != float64(2) || countRows[MockAccount](t, app) != 2 {
+		t.Errorf("confirmed bulk delete failed: %d %v", resp.Code, resp.Body)
+	}
+}
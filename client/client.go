@@ -0,0 +1,306 @@
+// Package client is a typed http client of resources served by simpleapi CrudConfig.Generate
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Client struct {
+	BaseURL string
+
+	// http.DefaultClient if nil
+	HTTPClient *http.Client
+
+	// added to every request, e.g. Authorization
+	Header http.Header
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Header:  http.Header{},
+	}
+}
+
+// Error is returned for non 2xx responses, Msg is taken from `msg` of the response body
+type Error struct {
+	StatusCode int
+	Msg        string
+	Body       map[string]any
+}
+
+func (e *Error) Error() string {
+
+	if e.Msg != "" {
+		return fmt.Sprintf("simpleapi: %d %s", e.StatusCode, e.Msg)
+	}
+
+	return fmt.Sprintf("simpleapi: unexpected status %d", e.StatusCode)
+}
+
+// Do sends a request to path relative to BaseURL and decodes json response into out if it's not nil
+func (c *Client) Do(ctx context.Context, method string, path string, query url.Values, body any, out any) error {
+
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("unable to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+
+	for key, values := range c.Header {
+		for _, it := range values {
+			req.Header.Add(key, it)
+		}
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+
+		respErr := &Error{
+			StatusCode: resp.StatusCode,
+		}
+
+		if json.Unmarshal(data, &respErr.Body) == nil {
+			respErr.Msg, _ = respErr.Body["msg"].(string)
+		}
+
+		return respErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return fmt.Errorf("unable to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// Filter is a filter tree in the same format as `filter` list param:
+// field -> value or {"op": .., "v": ..}, with `$and`, `$or` and `$not` groups
+type Filter map[string]any
+
+// Op builds an operator condition, e.g. Filter{"age": Op("gte", 18)}
+func Op(op string, v any) map[string]any {
+	return map[string]any{
+		"op": op,
+		"v":  v,
+	}
+}
+
+func And(filters ...Filter) Filter {
+	return Filter{"$and": filters}
+}
+
+func Or(filters ...Filter) Filter {
+	return Filter{"$or": filters}
+}
+
+func Not(filter Filter) Filter {
+	return Filter{"$not": filter}
+}
+
+// Paging holds list params besides filter, zero values are not sent
+type Paging struct {
+	Page    int
+	PerPage int
+
+	// `-priority,created_at`
+	Sort string
+
+	SkipCount bool
+	Cursor    string
+
+	// sparse fieldset, fields to return
+	Fields []string
+}
+
+func (p Paging) values() url.Values {
+
+	values := url.Values{}
+
+	if p.Page > 0 {
+		values.Set("page", strconv.Itoa(p.Page))
+	}
+
+	if p.PerPage > 0 {
+		values.Set("per_page", strconv.Itoa(p.PerPage))
+	}
+
+	if p.Sort != "" {
+		values.Set("sort", p.Sort)
+	}
+
+	if p.SkipCount {
+		values.Set("skip_count", "true")
+	}
+
+	if p.Cursor != "" {
+		values.Set("cursor", p.Cursor)
+	}
+
+	if len(p.Fields) > 0 {
+		values.Set("fields", strings.Join(p.Fields, ","))
+	}
+
+	return values
+}
+
+// Page is a list response, Pages and TotalItems are empty when counting is skipped
+type Page[T any] struct {
+	Items []T `json:"items"`
+
+	HasMore    bool  `json:"has_more"`
+	Pages      int   `json:"pages"`
+	TotalItems int64 `json:"total_items"`
+
+	NextCursor string `json:"next_cursor"`
+	PrevCursor string `json:"prev_cursor"`
+}
+
+// Resource is a client of a single generated crud, T should decode object dtos
+type Resource[T any] struct {
+	client *Client
+	path   string
+}
+
+// NewResource creates a client of crud mounted at path, e.g. `/users/12/posts`
+func NewResource[T any](c *Client, path string) *Resource[T] {
+	return &Resource[T]{
+		client: c,
+		path:   "/" + strings.Trim(path, "/"),
+	}
+}
+
+func (r *Resource[T]) itemPath(id any) string {
+	return r.path + "/" + url.PathEscape(fmt.Sprintf("%v", id))
+}
+
+func (r *Resource[T]) list(ctx context.Context, values url.Values) (*Page[T], error) {
+
+	page := &Page[T]{}
+
+	err := r.client.Do(ctx, http.MethodGet, r.path, values, nil, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (r *Resource[T]) List(ctx context.Context, filter Filter, paging Paging) (*Page[T], error) {
+
+	values := paging.values()
+
+	if len(filter) > 0 {
+		encoded, err := json.Marshal(filter)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode filter: %w", err)
+		}
+		values.Set("filter", string(encoded))
+	}
+
+	return r.list(ctx, values)
+}
+
+// Query lists objects with a query template registered by QueryTemplate
+func (r *Resource[T]) Query(ctx context.Context, templateName string, args map[string]any, paging Paging) (*Page[T], error) {
+
+	values := paging.values()
+	values.Set("q", templateName)
+
+	if len(args) > 0 {
+		encoded, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode query args: %w", err)
+		}
+		values.Set("args", string(encoded))
+	}
+
+	return r.list(ctx, values)
+}
+
+func (r *Resource[T]) Get(ctx context.Context, id any) (obj T, err error) {
+
+	resp := struct {
+		Item *T `json:"item"`
+	}{Item: &obj}
+
+	err = r.client.Do(ctx, http.MethodGet, r.itemPath(id), nil, nil, &resp)
+
+	return
+}
+
+// Create sends data as is, so it should use fill names of fields
+func (r *Resource[T]) Create(ctx context.Context, data any) (obj T, err error) {
+
+	resp := struct {
+		Object *T `json:"object"`
+	}{Object: &obj}
+
+	err = r.client.Do(ctx, http.MethodPost, r.path, nil, data, &resp)
+
+	return
+}
+
+// Patch updates provided fields only and returns updated object
+func (r *Resource[T]) Patch(ctx context.Context, id any, patch any) (obj T, err error) {
+
+	resp := struct {
+		Item *T `json:"item"`
+	}{Item: &obj}
+
+	err = r.client.Do(ctx, http.MethodPatch, r.itemPath(id), nil, patch, &resp)
+
+	return
+}
+
+func (r *Resource[T]) Delete(ctx context.Context, id any) error {
+	return r.client.Do(ctx, http.MethodDelete, r.itemPath(id), nil, nil, nil)
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/dot5enko/simpleapi"
+	"github.com/tidwall/gjson"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type mockAppContext struct{}
+
+// model served by the test crud
+type noteModel struct {
+	Id    uint64
+	Title string `validate:"required"`
+	Views int
+}
+
+// dto as decoded by the client
+type note struct {
+	Id    uint64 `json:"id"`
+	Title string `json:"title"`
+	Views int    `json:"views"`
+}
+
+// crud of notes generated over a temp sqlite database, lastQuery gets query params of the latest request
+func mockServer(t *testing.T, lastQuery *map[string]string) *httptest.Server {
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("unable to open test db: %s", err.Error())
+	}
+
+	app := simpleapi.NewAppContext(&mockAppContext{})
+	app.Db = simpleapi.WrapGormDb(db, app)
+	app.Db.MigrateAll(&noteModel{})
+
+	group := simpleapi.NewCrudGroup(*app, simpleapi.CrudGroupConfig[mockAppContext]{
+		ObjectIdFieldName: "id",
+	})
+
+	mux := http.NewServeMux()
+
+	simpleapi.New(group, simpleapi.ServeMuxRouter(mux).Group("/notes"), noteModel{}).
+		AddQueryTemplate("popular", simpleapi.ListQueryParams{}, nil, func(args gjson.Result, filters simpleapi.HM) (simpleapi.HM, error) {
+			return simpleapi.HM{"views": simpleapi.HM{"op": "gte", "v": args.Get("min").Int()}}, nil
+		}, "min").
+		Generate()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		*lastQuery = map[string]string{}
+		for key := range r.URL.Query() {
+			(*lastQuery)[key] = r.URL.Query().Get(key)
+		}
+
+		mux.ServeHTTP(w, r)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestResource(t *testing.T) {
+
+	var lastQuery map[string]string
+
+	notes := NewResource[note](New(mockServer(t, &lastQuery).URL), "notes")
+
+	ctx := context.Background()
+
+	created, err := notes.Create(ctx, map[string]any{"title": "a", "views": 5})
+	if err != nil || created.Id != 1 || created.Title != "a" {
+		t.Fatalf("unexpected created object: %#+v, %v", created, err)
+	}
+
+	if _, err = notes.Create(ctx, map[string]any{"title": "b", "views": 1}); err != nil {
+		t.Fatalf("unable to create: %v", err)
+	}
+
+	page, err := notes.List(ctx, Filter{"title": Op("lookup", "a")}, Paging{Page: 1, Fields: []string{"id", "title"}})
+	if err != nil {
+		t.Fatalf("list failed: %s", err.Error())
+	}
+
+	if len(page.Items) != 1 || page.TotalItems != 1 || page.Items[0].Title != "a" || page.Items[0].Views != 0 {
+		t.Errorf("unexpected page: %#+v", page)
+	}
+
+	if lastQuery["filter"] != `{"title":{"op":"lookup","v":"a"}}` || lastQuery["fields"] != "id,title" {
+		t.Errorf("unexpected list params: %v", lastQuery)
+	}
+
+	page, err = notes.Query(ctx, "popular", map[string]any{"min": 3}, Paging{})
+	if err != nil || len(page.Items) != 1 || page.Items[0].Id != 1 || lastQuery["q"] != "popular" || lastQuery["args"] != `{"min":3}` {
+		t.Errorf("unexpected query template result: %#+v, %v, %v", page, lastQuery, err)
+	}
+
+	item, err := notes.Get(ctx, 1)
+	if err != nil || item.Title != "a" {
+		t.Errorf("unexpected item: %#+v, %v", item, err)
+	}
+
+	patched, err := notes.Patch(ctx, 1, map[string]any{"title": "c"})
+	if err != nil || patched.Title != "c" || patched.Views != 5 {
+		t.Errorf("unexpected patched object: %#+v, %v", patched, err)
+	}
+
+	if err = notes.Delete(ctx, 2); err != nil {
+		t.Errorf("unable to delete: %v", err)
+	}
+
+	_, err = notes.Get(ctx, 2)
+	if respErr, ok := err.(*Error); !ok || respErr.StatusCode != 404 || respErr.Msg != "object not found" {
+		t.Errorf("unexpected error for removed object: %v", err)
+	}
+
+	_, err = notes.Create(ctx, map[string]any{"views": 1})
+	respErr, ok := err.(*Error)
+	if !ok || respErr.StatusCode != 422 || respErr.Msg != "validation failed" {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	if fields, _ := respErr.Body["fields"].(map[string]any); fields["title"] == nil {
+		t.Errorf("invalid fields should be decoded from body: %v", respErr.Body)
+	}
+}
@@ -0,0 +1,78 @@
+// simpleapi-gen renders a typescript client from an OpenAPI document
+// served by CrudGroup.ServeOpenAPI:
+//
+//	simpleapi-gen -in http://localhost:8080/api/openapi.json -out src/api.ts
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/dot5enko/simpleapi"
+)
+
+type openapiDoc struct {
+	Resources []simpleapi.ResourceDoc `json:"x-simpleapi-resources"`
+}
+
+// reads document from a local file or an http(s) url
+func readDocument(source string) ([]byte, error) {
+
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func main() {
+
+	in := flag.String("in", "openapi.json", "openapi document, file path or url")
+	out := flag.String("out", "", "output file, stdout if empty")
+
+	flag.Parse()
+
+	data, err := readDocument(*in)
+	if err != nil {
+		log.Fatalf("unable to read document: %s", err.Error())
+	}
+
+	doc := openapiDoc{}
+
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		log.Fatalf("unable to decode document: %s", err.Error())
+	}
+
+	if len(doc.Resources) == 0 {
+		log.Fatalf("document has no x-simpleapi-resources, is it served by simpleapi ?")
+	}
+
+	source := simpleapi.TypeScriptClient(doc.Resources)
+
+	if *out == "" {
+		os.Stdout.WriteString(source)
+		return
+	}
+
+	err = os.WriteFile(*out, []byte(source), 0644)
+	if err != nil {
+		log.Fatalf("unable to write client: %s", err.Error())
+	}
+}
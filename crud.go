@@ -2,18 +2,20 @@ package simpleapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"net/http"
 	"reflect"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/dot5enko/typed"
-	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/tidwall/gjson"
+	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
 
@@ -31,12 +33,12 @@ type ModelIdFetcher[T any] func(obj *T) uint64
 
 // object id could be non integer
 type RelatedObjectIdGetter[T any] func(obj *T) any
-type RelatedItemsFetcher[OfType any, RelatedType any] func(ctx *gin.Context)
+type RelatedItemsFetcher[OfType any, RelatedType any] func(ctx Request)
 
 type HM = map[string]any
 
 type CrudConfig[T any, CtxType any] struct {
-	ParentGroup *gin.RouterGroup
+	ParentGroup Router
 	Model       T
 	App         *AppContext[CtxType]
 	CrudGroup   *CrudGroup[CtxType]
@@ -46,16 +48,29 @@ type CrudConfig[T any, CtxType any] struct {
 	tableName       string
 	primaryIdDbName string
 
+	// columns stored in db table
+	dbColumns map[string]bool
+
 	TypeDataModel FieldsMapping
 
-	requestDataGeneratorOverride func(g *gin.Context, ctx *AppContext[CtxType]) RequestData
+	requestDataGeneratorOverride func(g Request, ctx *AppContext[CtxType]) RequestData
 
-	existing     []gin.HandlerFunc
-	beforeCreate []gin.HandlerFunc
+	existing     []HandlerFunc
+	beforeCreate []HandlerFunc
 
 	objectCreate func(ctx CrudContext[T, CtxType], obj *T) error
 	afterCreate  func(ctx *AppContext[CtxType], obj *T) error
 
+	beforeDelete func(ctx CrudContext[T, CtxType], obj *T) error
+	afterDelete  func(ctx *AppContext[CtxType], obj *T) error
+
+	// write change history, see Audit()
+	audit bool
+	// keep entity snapshots, see Revisions()
+	revisions bool
+	// replay window of write requests with Idempotency-Key, see Idempotent()
+	idempotencyWindow time.Duration
+
 	relTypeTable string
 
 	hasMultiple []ApiObjectRelation[T, CtxType]
@@ -71,11 +86,21 @@ type CrudConfig[T any, CtxType any] struct {
 
 	paging PagingConfig
 
+	bulk BulkConfig
+
 	predefinedQueries map[string]predefinedQuery
 }
 
 type PagingConfig struct {
 	PerPage int
+
+	// do not run a count query for list requests.
+	// responses contain only `has_more` instead of `pages` and `total_items`
+	SkipCount bool
+
+	// keyset paging: list accepts `cursor` instead of `page` and
+	// returns `next_cursor`/`prev_cursor`. total count is never calculated
+	Cursor bool
 }
 
 type HasManyConfig[T any] struct {
@@ -115,7 +140,7 @@ func (it *CrudConfig[T, CtxType]) HasManyFilter(filter string) (*HasManyConfig[C
 	return nil, false
 }
 
-func (it *CrudConfig[T, CtxType]) RequestData(g *gin.Context) RequestData {
+func (it *CrudConfig[T, CtxType]) RequestData(g Request) RequestData {
 
 	ctx := &it.CrudGroup.Ctx
 
@@ -149,7 +174,7 @@ func (it *CrudConfig[T, CtxType]) IdField(fieldName string) *CrudConfig[T, CtxTy
 	return it
 }
 
-func (it *CrudConfig[T, CtxType]) AdminCheck(init func(g *gin.Context, ctx *AppContext[CtxType]) RequestData) *CrudConfig[T, CtxType] {
+func (it *CrudConfig[T, CtxType]) AdminCheck(init func(g Request, ctx *AppContext[CtxType]) RequestData) *CrudConfig[T, CtxType] {
 
 	it.requestDataGeneratorOverride = init
 
@@ -180,6 +205,10 @@ type EndpointsDisableConfig struct {
 	Get    bool
 	Update bool
 	Delete bool
+
+	BulkCreate bool
+	BulkUpdate bool
+	BulkDelete bool
 }
 
 func (it *CrudConfig[T, CtxType]) Disable(config EndpointsDisableConfig) *CrudConfig[T, CtxType] {
@@ -212,18 +241,32 @@ func (it *CrudConfig[T, CtxType]) OnAfterCreate(h func(appctx *AppContext[CtxTyp
 	return it
 }
 
-func (it *CrudConfig[T, CtxType]) UseExisting(h ...gin.HandlerFunc) *CrudConfig[T, CtxType] {
+// h could veto removal by returning an error, invoked for soft removal and purge
+func (it *CrudConfig[T, CtxType]) OnBeforeDelete(h func(crudContext CrudContext[T, CtxType], obj *T) error) *CrudConfig[T, CtxType] {
+	it.beforeDelete = h
+	return it
+}
+
+func (it *CrudConfig[T, CtxType]) OnAfterDelete(h func(appctx *AppContext[CtxType], obj *T) error) *CrudConfig[T, CtxType] {
+	it.afterDelete = h
+	return it
+}
+
+// UseExisting sets middlewares run on a single object loaded by `:id` route param.
+// they can't be applied to bulk changes, so PATCH and DELETE by filter
+// on the collection are not registered when any are set
+func (it *CrudConfig[T, CtxType]) UseExisting(h ...HandlerFunc) *CrudConfig[T, CtxType] {
 
 	it.existing = h
 	return it
 }
 
-func (it *CrudConfig[T, CtxType]) UseBeforeCreate(h ...gin.HandlerFunc) *CrudConfig[T, CtxType] {
+func (it *CrudConfig[T, CtxType]) UseBeforeCreate(h ...HandlerFunc) *CrudConfig[T, CtxType] {
 	it.beforeCreate = h
 	return it
 }
 
-func New[T any, CtxType any](crudGroup *CrudGroup[CtxType], group *gin.RouterGroup, model T) *CrudConfig[T, CtxType] {
+func New[T any, CtxType any](crudGroup *CrudGroup[CtxType], group Router, model T) *CrudConfig[T, CtxType] {
 
 	modelData := crudGroup.Ctx.ApiData(model)
 
@@ -236,6 +279,19 @@ func New[T any, CtxType any](crudGroup *CrudGroup[CtxType], group *gin.RouterGro
 
 	primaryField := tableInfo.PrimaryFields[0]
 
+	if softDeleteField := modelData.SoftDeleteField; softDeleteField.Has {
+		fieldType := modelData.Fields[softDeleteField.DeclName].NativeType
+
+		if !softDeleteTypeSupported(softDeleteField.Kind, fieldType) {
+			panic(fmt.Sprintf("unsupported soft delete field type %s of %s, use bool, integer or time", fieldType, softDeleteField.DeclName))
+		}
+	}
+
+	dbColumns := map[string]bool{}
+	for _, it := range tableInfo.DBNames {
+		dbColumns[it] = true
+	}
+
 	result := CrudConfig[T, CtxType]{
 		ParentGroup: group,
 		Model:       model,
@@ -244,6 +300,7 @@ func New[T any, CtxType any](crudGroup *CrudGroup[CtxType], group *gin.RouterGro
 
 		tableName:       tableInfo.Table,
 		primaryIdDbName: primaryField.DBName,
+		dbColumns:       dbColumns,
 
 		// todo remove
 		objectIdField:           crudGroup.Config.ObjectIdFieldName,
@@ -253,13 +310,17 @@ func New[T any, CtxType any](crudGroup *CrudGroup[CtxType], group *gin.RouterGro
 			PerPage: 30,
 		},
 
+		bulk: BulkConfig{
+			BatchSize: 100,
+			MaxItems:  1000,
+		},
+
 		TypeDataModel: modelData,
 
 		predefinedQueries: map[string]predefinedQuery{},
 	}
 
 	// todo check rights in all methods
-	// todo recover in userland hooks
 
 	return &result
 }
@@ -298,83 +359,137 @@ func SetListFilterHandler(fname string, h FilterOperationHandler) {
 
 func (result *CrudConfig[T, CtxType]) DeleteEntity(appctx *AppContext[CtxType], modelCopy T, reqData RequestData) (respData *RespErr) {
 
-	respData = &RespErr{
-		Data: map[string]any{},
+	respData = result.removeEntity(appctx, modelCopy, reqData, false)
+
+	if respData.Httpcode == 200 && result.TypeDataModel.SoftDeleteField.Has {
+		respData.Data["msg"] = "soft removed"
 	}
 
-	responseData := respData.Data
+	return
+}
 
-	if result.TypeDataModel.SoftDeleteField.Has {
-		// soft removable items are not actually deleted
+// ErrDeleteRejected wraps errors returned by delete hooks
+var ErrDeleteRejected = errors.New("removal rejected")
 
-		// todo make it somewhat clear what is going on here
-		fname := result.TypeDataModel.SoftDeleteField.FillName
-		dto := gjson.Parse(fmt.Sprintf(`{"%s":1}`, fname))
+// removes object in a transaction with delete hooks, purge removes soft removable objects permanently
+func (result *CrudConfig[T, CtxType]) removeEntity(appctx *AppContext[CtxType], modelCopy T, reqData RequestData, purge bool) (respData *RespErr) {
 
-		reqData.log_format("filling soft removed field : %s", fname)
+	original := modelCopy
 
-		fillError := appctx.FillEntityFromDto(result.TypeDataModel, &modelCopy, dto, nil, reqData)
+	deleteErr := appctx.DbTransaction(func(isolatedContext AppContext[CtxType]) error {
 
-		if fillError != nil {
+		var removed *T
+		action := AuditDelete
 
-			responseData["msg"] = "fill object fields error"
+		if purge {
+			action = AuditPurge
+		}
 
-			if reqData.IsAdmin {
-				responseData["err"] = fillError.Error()
-			}
+		if result.beforeDelete != nil {
 
-			respData.Httpcode = 500
+			crudCtx := CrudContext[T, CtxType]{
+				App:  &isolatedContext,
+				Crud: result,
+			}
 
-			return
+			errDelete := callHook("OnBeforeDelete", func() error { return result.beforeDelete(crudCtx, &modelCopy) })
+			if errDelete != nil {
+				return fmt.Errorf("%w: %s", ErrDeleteRejected, errDelete.Error())
+			}
 		}
 
-		// update only selected fields
-		updateErr := appctx.Db.UpdateFields(&modelCopy, result.TypeDataModel.SoftDeleteField.TableColumnName)
-		if updateErr != nil {
+		// versioned objects are removed only if nobody saved them since they were loaded
+		versionField := result.TypeDataModel.VersionField
 
-			respData.Httpcode = 500
+		if purge || !result.TypeDataModel.SoftDeleteField.Has {
 
-			responseData["msg"] = "unable to soft remove"
+			var deleteErr error
 
-			if reqData.IsAdmin {
-				responseData["err"] = updateErr.Error()
+			if versionField.Has {
+				version := reflect.ValueOf(modelCopy).FieldByName(versionField.DeclName).Interface()
+				deleteErr = isolatedContext.Db.DeleteVersioned(&modelCopy, versionField.TableColumnName, version, purge)
+			} else if purge {
+				deleteErr = isolatedContext.Db.Purge(&modelCopy)
+			} else {
+				deleteErr = isolatedContext.Db.Delete(&modelCopy)
 			}
 
-			return
+			if deleteErr != nil {
+				return deleteErr
+			}
 		} else {
+			// soft removable items are not actually deleted
 
-			respData.Httpcode = 200
+			reqData.log_format("stamping soft removed field : %s", result.TypeDataModel.SoftDeleteField.FillName)
 
-			responseData["ok"] = true
-			responseData["msg"] = "soft removed"
+			columns, stampErr := result.stampSoftDelete(&modelCopy, true, reqData)
+			if stampErr != nil {
+				return stampErr
+			}
 
-			return
-		}
+			var deleteErr error
 
-	} else {
+			// update only selected fields
+			if versionField.Has {
+				prevVersion, versionErr := incrementVersion(&modelCopy, versionField.DeclName)
+				if versionErr != nil {
+					return versionErr
+				}
 
-		deleteError := appctx.Db.Delete(&modelCopy)
-		if deleteError != nil {
+				columns = append(columns, versionField.TableColumnName)
+				deleteErr = isolatedContext.Db.SoftDeleteVersioned(&modelCopy, versionField.TableColumnName, prevVersion, columns...)
+			} else {
+				deleteErr = isolatedContext.Db.SoftDelete(&modelCopy, columns...)
+			}
 
-			respData.Httpcode = 500
+			if deleteErr != nil {
+				return deleteErr
+			}
 
-			responseData["msg"] = "unable to soft remove"
+			removed = &modelCopy
+		}
 
-			if reqData.IsAdmin {
-				responseData["err"] = deleteError.Error()
+		if result.afterDelete != nil {
+			afterDeleteErr := callHook("OnAfterDelete", func() error { return result.afterDelete(&isolatedContext, &modelCopy) })
+			if afterDeleteErr != nil {
+				return fmt.Errorf("%w: %s", ErrDeleteRejected, afterDeleteErr.Error())
 			}
+		}
 
-			return
-		} else {
-			respData.Httpcode = 200
-			responseData["ok"] = true
+		return result.recordChange(&isolatedContext, action, &original, removed, reqData)
+	})
+
+	if deleteErr != nil {
+
+		if errors.Is(deleteErr, ErrVersionConflict) {
+			return versionConflictResp()
+		}
+
+		// hook messages are meant for the client
+		if errors.Is(deleteErr, ErrDeleteRejected) {
+			return NewRespErr(409, HM{
+				"msg": "unable to remove",
+				"err": deleteErr.Error(),
+			})
+		}
+
+		responseData := HM{
+			"msg": "unable to remove",
 		}
+
+		if reqData.IsAdmin {
+			responseData["err"] = deleteErr.Error()
+		}
+
+		return NewRespErr(500, responseData)
 	}
 
-	return
+	return NewRespErr(200, HM{
+		"ok": true,
+	})
 }
 
-func (result *CrudConfig[T, CtxType]) CreateEntity(appctx *AppContext[CtxType], ctx *gin.Context, parsedJson gjson.Result, reqData RequestData) (objectCreated T, respData *RespErr) {
+func (result *CrudConfig[T, CtxType]) CreateEntity(appctx *AppContext[CtxType], ctx Request, parsedJson gjson.Result, reqData RequestData) (objectCreated T, respData *RespErr) {
 	var modelCopy T
 
 	fillError := appctx.FillEntityFromDto(result.TypeDataModel, &modelCopy, parsedJson, nil, reqData)
@@ -387,6 +502,21 @@ func (result *CrudConfig[T, CtxType]) CreateEntity(appctx *AppContext[CtxType],
 		return
 	}
 
+	stampErr := result.stampAuthor(&modelCopy, true, reqData)
+	if stampErr != nil {
+		respData = NewRespErr(500, HM{
+			"msg": "can't fill object with provided data",
+			"err": stampErr.Error(),
+		})
+		return
+	}
+
+	validationErrs := result.ValidateEntity(appctx, &modelCopy, reqData)
+	if len(validationErrs) > 0 {
+		respData = validationErrs.RespErr()
+		return
+	}
+
 	createdErr := appctx.DbTransaction(func(isolatedContext AppContext[CtxType]) error {
 
 		// todo check if object is used somewhere
@@ -397,7 +527,7 @@ func (result *CrudConfig[T, CtxType]) CreateEntity(appctx *AppContext[CtxType],
 				Crud: result,
 			}
 
-			errCreate := result.objectCreate(crudCtx, &modelCopy)
+			errCreate := callHook("OnObjectCreate", func() error { return result.objectCreate(crudCtx, &modelCopy) })
 			if errCreate != nil {
 				return fmt.Errorf("unable to perform pre object create hook: %s", errCreate.Error())
 			}
@@ -417,13 +547,13 @@ func (result *CrudConfig[T, CtxType]) CreateEntity(appctx *AppContext[CtxType],
 		// }
 
 		if result.afterCreate != nil {
-			afterCreateErr := result.afterCreate(&isolatedContext, &modelCopy)
+			afterCreateErr := callHook("OnAfterCreate", func() error { return result.afterCreate(&isolatedContext, &modelCopy) })
 			if afterCreateErr != nil {
-				return fmt.Errorf("unable to perform pre object create hook: %s", afterCreateErr.Error())
+				return fmt.Errorf("unable to perform after object create hook: %s", afterCreateErr.Error())
 			}
 		}
 
-		return nil
+		return result.recordChange(&isolatedContext, AuditCreate, nil, &modelCopy, reqData)
 	})
 
 	if createdErr != nil {
@@ -440,449 +570,500 @@ func (result *CrudConfig[T, CtxType]) CreateEntity(appctx *AppContext[CtxType],
 	})
 }
 
-func (result *CrudConfig[T, CtxType]) Generate() *CrudConfig[T, CtxType] {
+func (result *CrudConfig[T, CtxType]) UpdateEntity(appctx *AppContext[CtxType], modelCopy T, parsed gjson.Result, req RequestData) (objectUpdated T, respData *RespErr) {
 
-	group := result.ParentGroup
-	// model := result.Model
-	appctx := result.App
-
-	hasAdminOnlyFiedls := false
-
-	for _, it := range result.TypeDataModel.Fields {
-		if it.AdminOnly {
-			hasAdminOnlyFiedls = true
-			break
-		}
+	if !result.versionMatches(modelCopy, parsed) {
+		respData = versionConflictResp()
+		return
 	}
 
-	if hasAdminOnlyFiedls && result.CrudGroup.Config.RequestDataGenerator == nil {
+	anotherCopy := modelCopy
+	ref := &anotherCopy
 
-		typ := reflect.Indirect(reflect.ValueOf(result.Model)).Type()
-		log.Printf("crud group type has adminOnly fields, but no rule provided on how to grant role, %#+v", typ.Name())
-	}
+	fillError := appctx.FillEntityFromDto(result.TypeDataModel, ref, parsed, nil, req)
 
-	var writePermissionMiddleware gin.HandlerFunc = func(ctx *gin.Context) {
-		wp := result.CrudGroup.Config.WritePermission
-		if wp != nil {
-			hasPermission := (*wp)(ctx, appctx)
-			if !hasPermission {
-				ctx.AbortWithStatusJSON(403, HM{
-					"msg": "No write permission, code updated",
-				})
-				return
-			}
-		}
+	if fillError != nil {
+		respData = NewRespErr(500, HM{
+			"msg": "fill object fields erorr",
+			"err": fillError.Error(),
+		})
+		return
 	}
 
-	// todo make at compile time
-	rp := result.CrudGroup.Config.ReadPermission
-	if rp != nil {
-		group.Use(func(ctx *gin.Context) {
-			hasPermission := (*rp)(ctx, appctx)
-			if !hasPermission {
-				ctx.AbortWithStatusJSON(403, HM{
-					"msg": "No read permission",
-				})
-				return
-			}
+	stampErr := result.stampAuthor(ref, false, req)
+	if stampErr != nil {
+		respData = NewRespErr(500, HM{
+			"msg": "fill object fields erorr",
+			"err": stampErr.Error(),
 		})
+		return
 	}
 
-	if result.beforeCreate != nil {
-		group.Use(result.beforeCreate...)
+	validationErrs := result.ValidateEntity(appctx, ref, req)
+	if len(validationErrs) > 0 {
+		respData = validationErrs.RespErr()
+		return
 	}
 
-	// create
-	if !result.disableEndpoints.Create {
-		group.POST("", writePermissionMiddleware, func(ctx *gin.Context) {
+	saveError := appctx.DbTransaction(func(c AppContext[CtxType]) error {
 
-			// create new object
-			data, err := ctx.GetRawData()
-			if err != nil {
-				ctx.JSON(500, HM{
-					"msg": "unable to get object data, when creating new one",
-					"err": err.Error(),
-				})
-				return
+		var saveErr error
+
+		versionField := result.TypeDataModel.VersionField
+
+		if versionField.Has {
+			// save only if nobody saved the object since it was loaded
+			prevVersion, versionErr := incrementVersion(ref, versionField.DeclName)
+			if versionErr != nil {
+				return versionErr
 			}
 
-			// default fill from model tags
-			parsedJson := gjson.ParseBytes(data)
+			saveErr = c.Db.SaveVersioned(ref, versionField.TableColumnName, prevVersion)
+		} else {
+			saveErr = c.Db.Save(ref)
+		}
 
-			// req := result.RequestData(ctx)
-			reqData := result.RequestData(ctx)
+		// todo remove
+		if saveErr == nil {
 
-			_, result := result.CreateEntity(appctx, ctx, parsedJson, reqData)
+			fieldsData := result.TypeDataModel
 
-			if result == nil {
-				ctx.JSON(500, gin.H{
-					"msg": "unexpected response",
-				})
-			} else {
-				ctx.JSON(result.Httpcode, result.Data)
-			}
-		})
-	}
+			req.log_format("saved succesfully")
 
-	// get list
-	if !result.disableEndpoints.List {
-		group.GET("", func(ctx *gin.Context) {
+			if fieldsData.UpdateExtraMethod {
 
-			userAuthData := result.RequestData(ctx)
+				req.log_format("processing extra update method for entity")
+
+				objUpdater, _ := any(ref).(OnUpdateEventHandler[CtxType, T])
+				updateEventError := callHook("OnUpdate", func() error { return objUpdater.OnUpdate(&c, modelCopy, req) })
+				if updateEventError != nil {
 
-			listQueryParams := ListQueryParams{}
-			ctx.BindQuery(&listQueryParams)
+					req.log_format("rollback update due to OnUpdate: %s", updateEventError.Error())
+					return updateEventError
+				}
+			}
 
-			// TODO put into crud group state, no need to get it each time manually
-			modelDataStruct := result.TypeDataModel
+			saveErr = result.recordChange(&c, AuditUpdate, &modelCopy, ref, req)
+		} else {
+			req.log_format("got an error while saving item")
+		}
 
-			// filters
-			// decode userdata from query
-			filtersMap := HM{}
+		return saveErr
+	})
 
-			if listQueryParams.PredefinedQuery != "" {
-				var predefinedQErr *RespErr
+	if errors.Is(saveError, ErrVersionConflict) {
+		respData = versionConflictResp()
+		return
+	}
 
-				oldPage := listQueryParams.Page
+	if saveError != nil {
 
-				// overrides paging, sorting etc
-				filtersMap, listQueryParams, predefinedQErr = result.ParsePredefinedQuery(listQueryParams)
-				if predefinedQErr != nil {
-					ctx.JSON(predefinedQErr.Httpcode, predefinedQErr.Data)
-					return
-				}
+		repsJson := HM{
+			"msg": "unable to update object",
+		}
 
-				listQueryParams.Page = oldPage
+		if req.Debug {
+			repsJson["err"] = saveError.Error()
 
-			} else {
-				_filterValue := listQueryParams.Filter
-				json.Unmarshal([]byte(_filterValue), &filtersMap)
+			panickedErr, ok := saveError.(typed.PanickedError)
+			if ok {
+				repsJson["stack"] = panickedErr.Cause
 			}
+		}
 
-			filterCompiled := prepareFilterData[T, CtxType](filtersMap, result, modelDataStruct, userAuthData, listQueryParams)
+		respData = NewRespErr(500, repsJson)
+		return
+	}
 
-			if !filterCompiled.IsOk() {
-				ctx.JSON(200, HM{
-					"items":       []any{},
-					"pages":       0,
-					"total_items": 0,
-					"msg":         "no access",
-				})
-				return
-			}
+	return anotherCopy, NewRespErr(200, HM{
+		"item": ToDto(anotherCopy, appctx, req).Unwrap(),
+	})
+}
 
-			filterData := filterCompiled.Unwrap()
-			filtersSql := filterData.QueryPlaceholder
+// FindExisting loads an object by id, applying soft delete and user reference rules.
+// soft removed objects are hidden from non admins unless includeDeleted is set
+func (result *CrudConfig[T, CtxType]) FindExisting(appctx *AppContext[CtxType], idParam string, reqData RequestData, includeDeleted bool) (obj T, respData *RespErr) {
 
-			totalItems := int64(0)
+	modelInfo := result.TypeDataModel
 
-			// process complex filters
+	filter := map[string]any{
+		result.objectIdField: idParam,
+	}
 
-			var joinClause string = ""
-			var joinClauseArgs []any = []any{}
-			joinClauseWhereCond := ""
+	filterArgs := []any{}
+	filterEntries := []string{}
 
-			complexFiltersCount := len(filterData.ComplexFilters)
-			if complexFiltersCount > 0 {
-				userAuthData.log_format("processing %d complex filters ", complexFiltersCount)
+	// do not display removed items for non admins
+	if modelInfo.SoftDeleteField.Has && !reqData.IsAdmin && !includeDeleted {
+		softDeleteSql, softDeleteArgs := result.softDeleteCondition(false)
 
-				for _, it := range filterData.ComplexFilters {
+		filterEntries = append(filterEntries, softDeleteSql)
+		filterArgs = append(filterArgs, softDeleteArgs...)
+	}
 
-					userAuthData.log_format("processing filter for %s", it.fiedName)
+	// todo move to compile time
+	// eg generate Use(...) without ifs
+	if modelInfo.UserReferenceField.Has && !reqData.IsAdmin {
 
-					func() {
+		userId := reqData.AuthorizedUserId
 
-						defer func() {
-							rec := recover()
-							if rec != nil {
-								userAuthData.log_format("unable to process complex filter for field `%s`: %s", it.fiedName, rec)
-							}
-						}()
+		idResult := fmt.Sprintf("%v", userId)
 
-						val := it.inputValue
+		if userId == nil || idResult == "" {
+			respData = NewRespErr(404, HM{
+				"msg": "item not f0und",
+			})
+			return
+		} else {
+			// put user reference into filter
+			filter[modelInfo.UserReferenceField.TableColumnName] = userId
+		}
+	}
 
-						if it.filterData.InputTransformer != nil {
-							userAuthData.log_format(" filter has input transformer, applying...")
-							val = it.filterData.InputTransformer(appctx, val)
-						}
+	// build filters
+	// todo cache query
 
-						typ := reflect.TypeOf(uint64(0))
-						name := it.filterData.RelDestFieldName
+	for fName, fVal := range filter {
+		filterEntries = append(filterEntries, fmt.Sprintf("%s = ?", fName))
+		filterArgs = append(filterArgs, fVal)
+	}
 
-						fakeApiTags := ApiTags{
-							TableColumnName: fmt.Sprintf("%s.%s", it.filterData.RelTable, name),
-							TypeKind:        reflect.Uint64,
-							NativeType:      typ,
-							Typ:             typ.Name(),
-							Fillable:        true,
-							FillName:        nil,
-							Name:            &name,
-						}
+	// validate filter ?
 
-						processedComplexFieldSql, complexArgs, err := processFilterValueToSqlCond("", val, userAuthData, it.fiedName, fakeApiTags)
-						if err != nil {
-							userAuthData.log_format("unable to generate complex filter (%s) value :%s", it.fiedName, err.Error())
-						} else {
-							joinClauseArgs = append(joinClauseArgs, complexArgs)
-							joinClauseWhereCond = processedComplexFieldSql
+	filterStr := strings.Join(filterEntries, " AND ")
 
-							joinedTblName := it.filterData.RelTable
-							curTable := result.tableName
+	findErr := result.rawDb(appctx).Where(filterStr, filterArgs...).First(&obj).Error
 
-							joinClause = fmt.Sprintf("INNER JOIN %s ON %s = %s.%s", joinedTblName, it.RelFieldName(it.filterData.RelCurFieldName), curTable, result.objectIdField)
-						}
-					}()
-				}
-			}
+	if findErr != nil {
 
-			{
-				dbQ := appctx.Db.Raw()
+		eId := uuid.NewString()
 
-				finalSQLConds := filtersSql
-				finalArgs := filterData.Args
+		log.Printf("db err : %s: %s", eId, findErr.Error())
 
-				if joinClauseWhereCond != "" {
-					finalSQLConds += " AND " + joinClauseWhereCond
-					finalArgs = append(finalArgs, joinClauseArgs...)
-				}
+		respData = NewRespErr(404, HM{
+			"msg": "object not found",
+			"id":  eId,
+		})
+		return
+	}
 
-				// todo add field list for list request
+	return obj, nil
+}
 
-				qB := dbQ.Where(finalSQLConds, finalArgs...)
-				countQuery := appctx.Db.Raw().Table(result.tableName).Where(finalSQLConds, finalArgs...)
+// loads object from `id` route param into request context and runs UseExisting middlewares
+func (result *CrudConfig[T, CtxType]) loadExisting(ctx Request, includeDeleted bool) {
 
-				if joinClause != "" {
-					qB = qB.Joins(joinClause)
-					countQuery = countQuery.Joins(joinClause)
-				}
+	reqData := result.RequestData(ctx)
 
-				// todo cache
-				idField := fmt.Sprintf("%s.%s", result.tableName, result.primaryIdDbName)
+	modelCopy, findResp := result.FindExisting(result.App, ctx.Param("id"), reqData, includeDeleted)
 
-				countQuery.Distinct(idField).Count(&totalItems)
-				pagesCount := math.Ceil(float64(totalItems) / float64(filterData.PerPage))
+	if findResp != nil {
+		ctx.AbortWithStatusJSON(findResp.Httpcode, findResp.Data)
+	} else {
+		ctx.Set("_eobj", modelCopy)
+	}
 
-				userAuthData.log_format("requst SQL: %s", finalSQLConds)
+	if result.existing != nil {
+		for _, it := range result.existing {
+			if !ctx.IsAborted() {
+				it(ctx)
+			}
+		}
+	}
+}
 
-				sortOrder := "ASC"
-				if listQueryParams.SortOrder == -1 {
-					sortOrder = "DESC"
-				}
+// ListEntities loads a single page of entities matching list query params.
+// the page is fetched with one query, has-many filters are applied through
+// EXISTS subqueries so related rows never multiply the page
+func (result *CrudConfig[T, CtxType]) ListEntities(appctx *AppContext[CtxType], listQueryParams ListQueryParams, userAuthData RequestData) *RespErr {
 
-				if filterData.Limit > 0 {
-					qB = qB.Limit(filterData.Limit)
-				}
+	// TODO put into crud group state, no need to get it each time manually
+	modelDataStruct := result.TypeDataModel
 
-				if filterData.Offset > 0 {
-					qB = qB.Offset(filterData.Offset)
-				}
+	// filters
+	// decode userdata from query
+	filtersMap := HM{}
 
-				var sortFieldName string
+	if listQueryParams.PredefinedQuery != "" {
+		var predefinedQErr *RespErr
 
-				if listQueryParams.SortField != "" {
-					sortFieldName = fmt.Sprintf("%s.%s", result.tableName, listQueryParams.SortField)
+		requested := listQueryParams
 
-					sortOrderClause := fmt.Sprintf("%s %s", sortFieldName, sortOrder)
-					qB = qB.Order(sortOrderClause)
-				}
+		// overrides paging, sorting etc
+		filtersMap, listQueryParams, predefinedQErr = result.ParsePredefinedQuery(listQueryParams)
+		if predefinedQErr != nil {
+			return predefinedQErr
+		}
 
-				itemIds := []map[string]any{}
+		listQueryParams.Page = requested.Page
+		listQueryParams.Cursor = requested.Cursor
+		listQueryParams.Fields = requested.Fields
+		listQueryParams.SkipCount = listQueryParams.SkipCount || requested.SkipCount
 
-				finalQuery := qB.Table(result.tableName)
+		// template ordering is a default one
+		if requested.hasSort() {
+			listQueryParams.Sort = requested.Sort
+			listQueryParams.SortField = requested.SortField
+			listQueryParams.SortOrder = requested.SortOrder
+		}
 
-				if sortFieldName != "" {
-					finalQuery = finalQuery.Select(fmt.Sprintf("DISTINCT(%s)", idField), sortFieldName)
-				} else {
-					finalQuery = finalQuery.Distinct(idField)
-				}
+	} else {
+		_filterValue := listQueryParams.Filter
+		json.Unmarshal([]byte(_filterValue), &filtersMap)
+	}
 
-				respObject := finalQuery.Find(&itemIds)
+	filterCompiled := prepareFilterData[T, CtxType](filtersMap, result, modelDataStruct, userAuthData, listQueryParams)
 
-				findErr := respObject.Error
+	if !filterCompiled.IsOk() {
+		return NewRespErr(200, HM{
+			"items":       []any{},
+			"pages":       0,
+			"total_items": 0,
+			"msg":         "no access",
+		})
+	}
 
-				if findErr != nil {
+	filterData := filterCompiled.Unwrap()
+	finalSQLConds := filterData.QueryPlaceholder
+	finalArgs := filterData.Args
 
-					eId := uuid.NewString()
+	userAuthData.log_format("requst SQL: %s", finalSQLConds)
 
-					log.Printf("db err : %s: %s", eId, findErr.Error())
+	applyConds := func() *gorm.DB {
+		return result.rawDb(appctx).Table(result.tableName).Where(finalSQLConds, finalArgs...)
+	}
 
-					ctx.AbortWithStatusJSON(404, HM{
-						"msg": "db err",
-						"id":  eId,
-					})
-					return
-				} else {
+	totalItems := int64(0)
+	pagesCount := float64(0)
 
-					var items []T
+	cursorMode := result.paging.Cursor
 
-					// ids := []any{}
+	if cursorMode {
+		if column, nullable := result.nullableSortKey(filterData.Sort); nullable {
+			return NewRespErr(400, HM{
+				"msg":    "nullable field can't be used for sorting with cursor paging",
+				"column": column,
+			})
+		}
+	}
 
-					idQueryFormat := fmt.Sprintf("%s = ?", result.objectIdField)
+	countItems := !result.paging.SkipCount && !listQueryParams.SkipCount && !cursorMode
 
-					for _, rowItem := range itemIds {
+	if countItems {
+		countErr := applyConds().Count(&totalItems).Error
+		if countErr != nil {
+			userAuthData.log_format("unable to count items: %s", countErr.Error())
+		}
 
-						idValue := rowItem[result.objectIdField]
+		pagesCount = math.Ceil(float64(totalItems) / float64(filterData.PerPage))
+	}
 
-						// ids = append(ids,)
-						var item T
+	sortKeys := filterData.Sort
+	backward := false
+	hasCursor := false
 
-						errFindById := appctx.Db.Raw().First(&item, idQueryFormat, idValue).Error
-						if errFindById != nil {
-							userAuthData.log_format("unable to find item by id: %v %s", idValue, findErr.Error())
-						} else {
-							items = append(items, item)
-						}
-					}
+	if cursorMode {
+		sortKeys = result.cursorKeys(sortKeys)
+	}
 
-					{
-
-						dtos := []any{}
-						// convert to dto objects
-
-						for _, it := range items {
-
-							// check if item has dto converter
-							// todo pass permission value
-							_dtoResult := ToDto(it, appctx, userAuthData)
-							if _dtoResult.IsOk() {
-								unwrapped := _dtoResult.Unwrap()
-								dtos = append(dtos, unwrapped)
-							} else {
-								log.Printf("unable to convert object(%#+v) to api dto : %s", it, _dtoResult.UnwrapError().Error())
-							}
-						}
-
-						if userAuthData.Debug {
-							ctx.JSON(200, HM{
-								"items":       dtos,
-								"pages":       pagesCount,
-								"total_items": totalItems,
-								"logs":        userAuthData.getDebugLogs(),
-							})
-						} else {
-							ctx.JSON(200, HM{
-								"items":       dtos,
-								"pages":       pagesCount,
-								"total_items": totalItems,
-							})
-						}
-					}
+	if listQueryParams.Fields != "" {
+		userAuthData.SelectFields(parseFieldList(listQueryParams.Fields)...)
+	}
 
-					return
-				}
-			}
-		})
+	qB := applyConds()
+
+	// narrow select to requested fields if dto is built from columns only
+	selectColumns := result.selectColumns(userAuthData, sortKeys)
+	if len(selectColumns) > 0 {
+		qB = qB.Select(selectColumns)
+	} else {
+		qB = qB.Select(fmt.Sprintf("%s.*", result.tableName))
 	}
 
-	existingItems := group.Group("/:id")
-	existingItems.Use(func(ctx *gin.Context) {
+	if cursorMode {
 
-		modelInfo := result.TypeDataModel
+		// keyset paging, position is taken from cursor instead of page offset
+		if listQueryParams.Cursor != "" {
 
-		reqData := result.RequestData(ctx)
+			cursor, cursorErr := decodeCursor(listQueryParams.Cursor)
 
-		idParam := ctx.Param("id")
+			var keysetSql string
+			var keysetArgs []any
 
-		filter := map[string]any{
-			result.objectIdField: idParam,
-		}
+			if cursorErr == nil {
+				keysetSql, keysetArgs, cursorErr = result.keysetCondition(sortKeys, cursor)
+			}
 
-		if modelInfo.SoftDeleteField.Has {
-			// do not display removed items for non admins
-			if !reqData.IsAdmin {
-				filter[modelInfo.SoftDeleteField.TableColumnName] = 0
-			} else {
-				filtersMap := map[string]any{}
-				_filterValue := ctx.Query("filter")
-				json.Unmarshal([]byte(_filterValue), &filtersMap)
-
-				// if admin request forcely wants to query `removed` data - no problem
-				_, removeFilterExists := filtersMap[modelInfo.SoftDeleteField.FillName]
-				if !removeFilterExists {
-					// hide removed elements by default
-					filtersMap[modelInfo.SoftDeleteField.FillName] = 0
-				}
+			if cursorErr != nil {
+				return NewRespErr(400, HM{
+					"msg": "bad cursor",
+				})
 			}
+
+			hasCursor = true
+			backward = cursor.Backward
+
+			qB = qB.Where(keysetSql, keysetArgs...)
 		}
 
-		// userFilterForced := false
-		// userFilterKey := ""
+		filterData.Offset = 0
+	}
 
-		// todo move to compile time
-		// eg generate Use(...) without ifs
-		if modelInfo.UserReferenceField.Has && !reqData.IsAdmin {
+	if len(sortKeys) > 0 {
+		// backward cursor walks in reversed order, page is reversed back after fetch
+		qB = qB.Order(orderClause(result.tableName, sortKeys, backward))
+	}
 
-			userId := reqData.AuthorizedUserId
+	// fetch one extra row to know if there is a next page without counting
+	if filterData.Limit > 0 {
+		qB = qB.Limit(filterData.Limit + 1)
+	}
 
-			idResult := fmt.Sprintf("%v", userId)
+	if filterData.Offset > 0 {
+		qB = qB.Offset(filterData.Offset)
+	}
 
-			if userId == nil || idResult == "" {
-				ctx.AbortWithStatusJSON(404, HM{
-					"msg": "item not f0und",
-				})
-				return
-			} else {
+	items := []T{}
 
-				// userFilterForced = true
-				// userFilterKey = modelInfo.UserReferenceField.TableColumnName
-				// put user reference into filter
-				filter[modelInfo.UserReferenceField.TableColumnName] = userId
-			}
+	findErr := qB.Find(&items).Error
+
+	if findErr != nil {
+
+		eId := uuid.NewString()
+
+		log.Printf("db err : %s: %s", eId, findErr.Error())
+
+		return NewRespErr(404, HM{
+			"msg": "db err",
+			"id":  eId,
+		})
+	}
+
+	hasMore := false
+
+	if filterData.Limit > 0 && len(items) > filterData.Limit {
+		hasMore = true
+		items = items[:filterData.Limit]
+	}
+
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
 		}
+	}
 
-		// build filters
-		// todo cache query
+	dtos := []any{}
+	// convert to dto objects
 
-		filterArgs := []any{}
-		filterEntries := []string{}
+	for _, it := range items {
 
-		for fName, fVal := range filter {
-			filterEntries = append(filterEntries, fmt.Sprintf("%s = ?", fName))
-			filterArgs = append(filterArgs, fVal)
+		// check if item has dto converter
+		// todo pass permission value
+		_dtoResult := ToDto(it, appctx, userAuthData)
+		if _dtoResult.IsOk() {
+			unwrapped := _dtoResult.Unwrap()
+			dtos = append(dtos, unwrapped)
+		} else {
+			log.Printf("unable to convert object(%#+v) to api dto : %s", it, _dtoResult.UnwrapError().Error())
 		}
+	}
+
+	resp := HM{
+		"items":    dtos,
+		"has_more": hasMore,
+	}
 
-		// validate filter ?
+	if countItems {
+		resp["pages"] = pagesCount
+		resp["total_items"] = totalItems
+	}
 
-		filterStr := strings.Join(filterEntries, " AND ")
+	if cursorMode && len(items) > 0 {
 
-		findResult := FindFirstWhere[T](appctx.Db, filterStr, filterArgs...)
+		// going backward there are always items after the page,
+		// going forward there are items before the page if cursor was passed
+		hasNext := hasMore || backward
+		hasPrev := hasCursor && (!backward || hasMore)
 
-		if !findResult.IsOk() {
+		if hasNext {
+			resp["next_cursor"] = result.cursorFor(items[len(items)-1], sortKeys, false)
+		}
 
-			findErr := findResult.UnwrapError()
+		if hasPrev {
+			resp["prev_cursor"] = result.cursorFor(items[0], sortKeys, true)
+		}
 
-			eId := uuid.NewString()
+		resp["has_more"] = hasNext
+	}
 
-			log.Printf("db err : %s: %s", eId, findErr.Error())
+	return NewRespErr(200, resp)
+}
 
-			ctx.AbortWithStatusJSON(404, HM{
-				"msg": "object not found",
-				"id":  eId,
-			})
-		} else {
-			modelCopy := findResult.Unwrap()
-			ctx.Set("_eobj", modelCopy)
+func (result *CrudConfig[T, CtxType]) Generate() *CrudConfig[T, CtxType] {
+
+	group := result.ParentGroup
+	// model := result.Model
+	appctx := result.App
+
+	result.CrudGroup.register(result)
+
+	hasAdminOnlyFiedls := false
+
+	for _, it := range result.TypeDataModel.Fields {
+		if it.AdminOnly {
+			hasAdminOnlyFiedls = true
+			break
 		}
+	}
 
-		if result.existing != nil {
-			for _, it := range result.existing {
-				if !ctx.IsAborted() {
-					it(ctx)
-				}
+	if hasAdminOnlyFiedls && result.CrudGroup.Config.RequestDataGenerator == nil {
+
+		typ := reflect.Indirect(reflect.ValueOf(result.Model)).Type()
+		log.Printf("crud group type has adminOnly fields, but no rule provided on how to grant role, %#+v", typ.Name())
+	}
+
+	var writePermissionMiddleware HandlerFunc = func(ctx Request) {
+		wp := result.CrudGroup.Config.WritePermission
+		if wp != nil {
+			hasPermission := (*wp)(ctx, appctx)
+			if !hasPermission {
+				ctx.AbortWithStatusJSON(403, HM{
+					"msg": "No write permission, code updated",
+				})
+				return
 			}
 		}
-	})
+	}
 
-	if !result.disableEndpoints.Update {
-		existingItems.PATCH("", writePermissionMiddleware, func(ctx *gin.Context) {
+	// todo make at compile time
+	rp := result.CrudGroup.Config.ReadPermission
+	if rp != nil {
+		group.Use(func(ctx Request) {
+			hasPermission := (*rp)(ctx, appctx)
+			if !hasPermission {
+				ctx.AbortWithStatusJSON(403, HM{
+					"msg": "No read permission",
+				})
+				return
+			}
+		})
+	}
 
-			var modelCopy T
+	// before existing object is loaded, so retried removals are replayed too
+	if result.idempotencyWindow > 0 {
+		group.Use(result.idempotencyMiddleware(writePermissionMiddleware))
+	}
 
-			model, _ := ctx.Get("_eobj")
-			modelCopy = model.(T)
+	if result.beforeCreate != nil {
+		group.Use(result.beforeCreate...)
+	}
 
-			data, err := ctx.GetRawData()
+	// create
+	if !result.disableEndpoints.Create {
+		group.Handle(http.MethodPost, "", writePermissionMiddleware, func(ctx Request) {
 
+			// create new object
+			data, err := ctx.Body()
 			if err != nil {
 				ctx.JSON(500, HM{
 					"msg": "unable to get object data, when creating new one",
@@ -890,100 +1071,154 @@ func (result *CrudConfig[T, CtxType]) Generate() *CrudConfig[T, CtxType] {
 				})
 				return
 			}
-			parsed := gjson.ParseBytes(data)
 
-			if !parsed.Exists() {
-				ctx.JSON(500, HM{
-					"msg": "unable to decode object info",
-					"raw": string(data),
-				})
+			// default fill from model tags
+			parsedJson := gjson.ParseBytes(data)
+
+			// array body creates many objects at once
+			if parsedJson.IsArray() && !result.disableEndpoints.BulkCreate {
+				result.handleBulkCreate(ctx, parsedJson)
 				return
 			}
 
-			anotherCopy := modelCopy
-			ref := &anotherCopy
+			// req := result.RequestData(ctx)
+			reqData := result.RequestData(ctx)
 
-			req := result.RequestData(ctx)
+			_, result := result.CreateEntity(appctx, ctx, parsedJson, reqData)
 
-			var debugLogs *arrayLogger
+			if result == nil {
+				ctx.JSON(500, HM{
+					"msg": "unexpected response",
+				})
+			} else {
+				ctx.JSON(result.Httpcode, result.Data)
+			}
+		})
+	}
 
-			fillError := appctx.FillEntityFromDto(result.TypeDataModel, ref, parsed, nil, req)
+	if !result.disableEndpoints.Create && !result.disableEndpoints.BulkCreate {
+		group.Handle(http.MethodPost, "/bulk", writePermissionMiddleware, func(ctx Request) {
 
-			if fillError != nil {
+			data, err := ctx.Body()
+			if err != nil {
 				ctx.JSON(500, HM{
-					"msg": "fill object fields erorr",
-					"err": fillError.Error(),
+					"msg": "unable to get objects data, when creating new ones",
+					"err": err.Error(),
 				})
 				return
 			}
 
-			saveError := appctx.DbTransaction(func(c AppContext[CtxType]) error {
+			result.handleBulkCreate(ctx, gjson.ParseBytes(data))
+		})
+	}
 
-				saveErr := c.Db.Save(ref)
+	// per object middlewares from UseExisting can't be applied to bulk changes,
+	// so bulk update/delete by filter is available only without them
+	hasExistingMiddlewares := len(result.existing) > 0
 
-				// todo remove
-				if saveErr == nil {
+	if hasExistingMiddlewares {
+		bulkUpdate := !result.disableEndpoints.Update && !result.disableEndpoints.BulkUpdate
+		bulkDelete := !result.disableEndpoints.Delete && !result.disableEndpoints.BulkDelete
 
-					fieldsData := result.TypeDataModel
+		if bulkUpdate || bulkDelete {
+			log.Printf("%s has UseExisting middlewares, bulk update/delete by filter endpoints are not registered", result.tableName)
+		}
+	}
 
-					req.log_format("saved succesfully")
+	if !result.disableEndpoints.Update && !result.disableEndpoints.BulkUpdate && !hasExistingMiddlewares {
+		group.Handle(http.MethodPatch, "", writePermissionMiddleware, func(ctx Request) {
+			result.handleBulkByFilter(ctx, false)
+		})
+	}
 
-					if fieldsData.UpdateExtraMethod {
+	if !result.disableEndpoints.Delete && !result.disableEndpoints.BulkDelete && !hasExistingMiddlewares {
+		group.Handle(http.MethodDelete, "", writePermissionMiddleware, func(ctx Request) {
+			result.handleBulkByFilter(ctx, true)
+		})
+	}
 
-						req.log_format("processing extra update method for entity")
+	// get list
+	if !result.disableEndpoints.List {
+		group.Handle(http.MethodGet, "", func(ctx Request) {
 
-						objUpdater, _ := any(ref).(OnUpdateEventHandler[CtxType, T])
-						updateEventError := objUpdater.OnUpdate(&c, modelCopy, req)
-						if updateEventError != nil {
+			userAuthData := result.RequestData(ctx)
 
-							req.log_format("rollback update due to OnUpdate: %s", updateEventError.Error())
-							return updateEventError
-						}
-					}
-				} else {
-					req.log_format("got an error while saving item")
-				}
+			listQueryParams, ok := bindListQuery(ctx)
+			if !ok {
+				return
+			}
 
-				return saveErr
-			})
+			listResp := result.ListEntities(appctx, listQueryParams, userAuthData)
 
-			if saveError != nil {
+			if userAuthData.Debug {
+				listResp.Data["logs"] = userAuthData.getDebugLogs()
+			}
 
-				repsJson := HM{
-					"msg": "unable to update object",
-				}
+			ctx.JSON(listResp.Httpcode, listResp.Data)
+		})
+	}
 
-				if req.Debug {
-					repsJson["err"] = saveError.Error()
+	result.generateTrashEndpoints(group, writePermissionMiddleware)
 
-					panickedErr, ok := saveError.(typed.PanickedError)
-					if ok {
-						repsJson["stack"] = panickedErr.Cause
-					}
+	existingItems := group.Group("/:id")
+	existingItems.Use(func(ctx Request) {
+		result.loadExisting(ctx, false)
+	})
 
-					repsJson["logs"] = debugLogs.lines
-				}
-				ctx.JSON(500, repsJson)
-			} else {
+	result.generateHistoryEndpoint(existingItems)
+	result.generateRevisionEndpoints(existingItems, writePermissionMiddleware)
 
-				resultItem := ToDto(anotherCopy, appctx, req).Unwrap()
+	if !result.disableEndpoints.Update {
+		existingItems.Handle(http.MethodPatch, "", writePermissionMiddleware, func(ctx Request) {
 
-				_resultJson := HM{
-					"item": resultItem,
-				}
+			var modelCopy T
 
-				if req.Debug {
-					_resultJson["logs"] = debugLogs.lines
-				}
+			model, _ := ctx.Get("_eobj")
+			modelCopy = model.(T)
+
+			data, err := ctx.Body()
+
+			if err != nil {
+				ctx.JSON(500, HM{
+					"msg": "unable to get object data, when creating new one",
+					"err": err.Error(),
+				})
+				return
+			}
+			parsed := gjson.ParseBytes(data)
+
+			if !parsed.Exists() {
+				ctx.JSON(500, HM{
+					"msg": "unable to decode object info",
+					"raw": string(data),
+				})
+				return
+			}
+
+			req := result.RequestData(ctx)
+
+			if !etagMatches(ctx.GetHeader("If-Match"), result.ETag(modelCopy)) {
+				conflict := versionConflictResp()
+				ctx.JSON(conflict.Httpcode, conflict.Data)
+				return
+			}
+
+			updated, updateResp := result.UpdateEntity(appctx, modelCopy, parsed, req)
+
+			if updateResp.Httpcode == 200 {
+				ctx.Header("ETag", result.ETag(updated))
+			}
 
-				ctx.JSON(200, _resultJson)
+			if req.Debug {
+				updateResp.Data["logs"] = req.getDebugLogs()
 			}
 
+			ctx.JSON(updateResp.Httpcode, updateResp.Data)
 		})
 	}
 
 	if !result.disableEndpoints.Delete {
-		existingItems.DELETE("", writePermissionMiddleware, func(ctx *gin.Context) {
+		existingItems.Handle(http.MethodDelete, "", writePermissionMiddleware, func(ctx Request) {
 
 			reqData := result.RequestData(ctx)
 
@@ -1004,7 +1239,29 @@ func (result *CrudConfig[T, CtxType]) Generate() *CrudConfig[T, CtxType] {
 			model, _ := ctx.Get("_eobj")
 			modelCopy = model.(T)
 
-			delResp := result.DeleteEntity(appctx, modelCopy, reqData)
+			if !etagMatches(ctx.GetHeader("If-Match"), result.ETag(modelCopy)) {
+				conflict := versionConflictResp()
+
+				responseData = conflict.Data
+				responseHttpCode = conflict.Httpcode
+				return
+			}
+
+			var delResp *RespErr
+
+			if isPurgeRequest(ctx) {
+				if !reqData.IsAdmin {
+					responseData = HM{
+						"msg": "only admin can purge objects",
+					}
+					responseHttpCode = 403
+					return
+				}
+
+				delResp = result.PurgeEntity(appctx, modelCopy, reqData)
+			} else {
+				delResp = result.DeleteEntity(appctx, modelCopy, reqData)
+			}
 
 			responseData = delResp.Data
 			responseHttpCode = delResp.Httpcode
@@ -1012,7 +1269,7 @@ func (result *CrudConfig[T, CtxType]) Generate() *CrudConfig[T, CtxType] {
 	}
 
 	if !result.disableEndpoints.Get {
-		existingItems.GET("", func(ctx *gin.Context) {
+		existingItems.Handle(http.MethodGet, "", func(ctx Request) {
 
 			reqData := result.RequestData(ctx)
 
@@ -1023,12 +1280,16 @@ func (result *CrudConfig[T, CtxType]) Generate() *CrudConfig[T, CtxType] {
 			model, _ := ctx.Get("_eobj")
 			modelCopy = model.(T)
 
-			// todo get role
+			fieldsParam := ctx.Query("fields")
+			if fieldsParam != "" {
+				reqData.SelectFields(parseFieldList(fieldsParam)...)
+			}
 
 			dur := time.Since(start)
 			durFloat := float64(dur.Nanoseconds()) / 1e6
 
-			ctx.Writer.Header().Add("Server-Timing", fmt.Sprintf("miss, app;dur=%.2f", durFloat))
+			ctx.Writer().Header().Add("Server-Timing", fmt.Sprintf("miss, app;dur=%.2f", durFloat))
+			ctx.Header("ETag", result.ETag(modelCopy))
 
 			ctx.JSON(200, HM{
 				"item": ToDto(modelCopy, appctx, reqData).Unwrap(),
@@ -1040,7 +1301,7 @@ func (result *CrudConfig[T, CtxType]) Generate() *CrudConfig[T, CtxType] {
 
 		cur := relatedItem
 
-		existingItems.GET("/"+cur.PathSuffix, func(ctx *gin.Context) {
+		existingItems.Handle(http.MethodGet, "/"+cur.PathSuffix, func(ctx Request) {
 
 			isolated := &AppContext[CtxType]{
 				Db:   appctx.Db,
@@ -1059,3 +1320,14 @@ func (result *CrudConfig[T, CtxType]) PerPage(val int) *CrudConfig[T, CtxType] {
 	result.paging.PerPage = val
 	return result
 }
+
+func (result *CrudConfig[T, CtxType]) SkipCount() *CrudConfig[T, CtxType] {
+	result.paging.SkipCount = true
+	return result
+}
+
+// CursorPaging enables keyset paging, sorting by nullable fields is rejected with 400
+func (result *CrudConfig[T, CtxType]) CursorPaging() *CrudConfig[T, CtxType] {
+	result.paging.Cursor = true
+	return result
+}
@@ -1,20 +1,42 @@
 package simpleapi
 
-import "github.com/gin-gonic/gin"
-
 type CrudGroup[T any] struct {
 	Ctx    AppContext[T]
 	Config CrudGroupConfig[T]
+
+	// configs registered by Generate, in order
+	resources []crudResource
+}
+
+// implemented by every CrudConfig, used to build api documents
+type crudResource interface {
+	resourceInfo() ResourceInfo
+}
+
+// Resources describes every generated crud of the group
+func (g *CrudGroup[T]) Resources() []ResourceInfo {
+
+	result := []ResourceInfo{}
+
+	for _, it := range g.resources {
+		result = append(result, it.resourceInfo())
+	}
+
+	return result
+}
+
+func (g *CrudGroup[T]) register(resource crudResource) {
+	g.resources = append(g.resources, resource)
 }
 
-type HasPermissionChecker[T any] func(req *gin.Context, ctx *AppContext[T]) bool
+type HasPermissionChecker[T any] func(req Request, ctx *AppContext[T]) bool
 
 type CrudGroupConfig[T any] struct {
 	ObjectIdFieldName string
 
 	WritePermission      *HasPermissionChecker[T]
 	ReadPermission       *HasPermissionChecker[T]
-	RequestDataGenerator func(g *gin.Context, ctx *AppContext[T]) RequestData
+	RequestDataGenerator func(g Request, ctx *AppContext[T]) RequestData
 }
 
 func NewCrudGroup[T any](ctx AppContext[T], config CrudGroupConfig[T]) *CrudGroup[T] {
@@ -12,9 +12,8 @@ import (
 func NewAppContext[T any](ctx *T) *AppContext[T] {
 
 	app := &AppContext[T]{
-		Data:        ctx,
-		objects:     map[string]FieldsMapping{},
-		AfterCommit: &[]func(){},
+		Data:    ctx,
+		objects: map[string]FieldsMapping{},
 	}
 
 	return app
@@ -23,10 +22,14 @@ func NewAppContext[T any](ctx *T) *AppContext[T] {
 type RequestData struct {
 	IsAdmin          bool
 	RoleGroup        uint8
-	AuthorizedUserId any // todo use generic type
+	Role             string // registered role name, resolved from RoleGroup if empty
+	AuthorizedUserId any    // todo use generic type
 
 	Debug bool
 
+	// requested output fields, all if empty
+	outFields map[string]bool
+
 	_logger         *log.Logger
 	_rawDebugLogger *arrayLogger
 }
@@ -81,14 +84,20 @@ type AppContext[T any] struct {
 
 	isolated bool
 
+	// callbacks of the transaction ctx is bound to, each transaction has its own list
 	AfterCommit *[]func()
 }
 
+// OnCommit defers cb until the transaction is committed,
+// outside of a transaction there is nothing to wait for, so cb is invoked right away
 func (d *AppContext[T]) OnCommit(cb func()) *AppContext[T] {
 
-	*d.AfterCommit = append(*d.AfterCommit, cb)
+	if !d.isolated || d.AfterCommit == nil {
+		cb()
+		return d
+	}
 
-	log.Printf(" cbs : %p", d.AfterCommit)
+	*d.AfterCommit = append(*d.AfterCommit, cb)
 
 	return d
 }
@@ -151,9 +160,7 @@ func (c AppContext[T]) FillEntityFromDto(modelTypeData FieldsMapping, obj any, d
 
 			fieldInfo := m.Fields[_fieldName]
 
-			// todo optimize
-			// make groups inheritance, etc
-			if fieldInfo.WriteRole > 0 && fieldInfo.WriteRole != uint64(req.RoleGroup) {
+			if !fieldInfo.Writable(req) {
 
 				req.log(func(logger *log.Logger) {
 					logger.Printf(" [%s] skipped filling because user nor admin not it has needed group to write this field", _fieldName)
@@ -250,14 +257,31 @@ func Migrate[T any, ObjectT any](c DbWrapper[T], object ObjectT) {
 	c.app.objects[objTypeName] = el
 }
 
+// ctx bound to a transaction, with its own after commit callbacks.
+// db wrapper refers to the copy, so nested calls stay within the transaction
 func (c AppContext[T]) isolateDatabase(isolatedDb *gorm.DB) AppContext[T] {
 
 	result := c
 	result.Db.setRaw(isolatedDb)
+	result.isolated = true
+	result.AfterCommit = &[]func(){}
+	result.Db.app = &result
 
 	return result
 }
 
+// copy of ctx detached from its transaction
+func (c AppContext[T]) detached() *AppContext[T] {
+
+	result := c
+	result.Db.setRaw(c.Db.topDb)
+	result.isolated = false
+	result.AfterCommit = nil
+	result.Db.app = &result
+
+	return &result
+}
+
 // a little bit of abstractions
 type TransactionProcessor[T any] func(c AppContext[T]) error
 
@@ -265,47 +289,34 @@ func (c AppContext[T]) DbTransaction(processor TransactionProcessor[T]) error {
 
 	if c.isolated {
 		return processor(c)
-	} else {
-
-		var isolatedCtx AppContext[T]
-
-		result := c.Db.Raw().Transaction(func(tx *gorm.DB) error {
+	}
 
-			isolatedCtx = c.isolateDatabase(tx)
-			isolatedCtx.isolated = true
-			
-			// do not use callbacks from parent if any
-			// how thats possible ?
-			*isolatedCtx.AfterCommit = []func(){}
+	var isolatedCtx AppContext[T]
 
-			processed := processor(isolatedCtx)
+	result := c.Db.Raw().Transaction(func(tx *gorm.DB) error {
 
-			return processed
-		})
+		isolatedCtx = c.isolateDatabase(tx)
 
-		if result == nil {
-			if len(*isolatedCtx.AfterCommit) > 0 {
+		return processor(isolatedCtx)
+	})
 
-				for _, it := range *isolatedCtx.AfterCommit {
-					func() {
+	if result != nil {
+		return result
+	}
 
-						defer func() {
-							rec := recover()
-							if rec != nil {
-								log.Printf("unable to perform after commit callback : %v", rec)
-							}
-						}()
+	for _, it := range *isolatedCtx.AfterCommit {
+		func() {
 
-						it()
-					}()
+			defer func() {
+				rec := recover()
+				if rec != nil {
+					log.Printf("unable to perform after commit callback : %v", rec)
 				}
+			}()
 
-			}
-		}
-
-		*isolatedCtx.AfterCommit = []func(){}
-
-		return result
-
+			it()
+		}()
 	}
+
+	return nil
 }
@@ -0,0 +1,195 @@
+package simpleapi
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var ErrBadCursor = fmt.Errorf("malformed cursor")
+
+// single column of list ordering
+type sortKey struct {
+	Column string
+	Desc   bool
+}
+
+// opaque position in a sorted list: values of sort keys and primary key of an item
+type listCursor struct {
+	Values []json.RawMessage `json:"v"`
+
+	// cursor points to items before the position
+	Backward bool `json:"b,omitempty"`
+}
+
+func encodeCursor(values []any, backward bool) string {
+
+	raw := make([]json.RawMessage, 0, len(values))
+
+	for _, it := range values {
+		encoded, _ := json.Marshal(it)
+		raw = append(raw, encoded)
+	}
+
+	data, _ := json.Marshal(listCursor{
+		Values:   raw,
+		Backward: backward,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) (result listCursor, err error) {
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return result, ErrBadCursor
+	}
+
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return result, ErrBadCursor
+	}
+
+	return
+}
+
+func orderClause(tableName string, keys []sortKey, flip bool) string {
+
+	parts := []string{}
+
+	for _, it := range keys {
+
+		desc := it.Desc != flip
+
+		order := "ASC"
+		if desc {
+			order = "DESC"
+		}
+
+		parts = append(parts, fmt.Sprintf("%s.%s %s", tableName, it.Column, order))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// sort keys made unique by appending primary key, needed for stable keyset paging
+func (result *CrudConfig[T, CtxType]) cursorKeys(keys []sortKey) []sortKey {
+
+	pkDesc := false
+
+	for _, it := range keys {
+		if it.Column == result.primaryIdDbName {
+			return keys
+		}
+
+		pkDesc = it.Desc
+	}
+
+	withPk := append([]sortKey{}, keys...)
+
+	return append(withPk, sortKey{
+		Column: result.primaryIdDbName,
+		Desc:   pkDesc,
+	})
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// first sort key whose column could hold NULL. keyset conditions compare with `=` and `>`,
+// which never match NULL, so rows would silently disappear from following pages
+func (result *CrudConfig[T, CtxType]) nullableSortKey(keys []sortKey) (string, bool) {
+
+	for _, it := range keys {
+
+		fieldInfo, ok := result.TypeDataModel.FieldByColumn(it.Column)
+		if !ok {
+			continue
+		}
+
+		typ := fieldInfo.NativeType
+
+		// pointers, sql.Null* and gorm.DeletedAt
+		if typ.Kind() == reflect.Pointer || typ.Implements(valuerType) {
+			return it.Column, true
+		}
+	}
+
+	return "", false
+}
+
+func (result *CrudConfig[T, CtxType]) cursorFor(item T, keys []sortKey, backward bool) string {
+
+	reflected := reflect.ValueOf(item)
+	values := []any{}
+
+	for _, it := range keys {
+
+		var value any
+
+		declName, ok := result.TypeDataModel.DeclaredByColumn(it.Column)
+		if ok {
+			value = reflected.FieldByName(declName).Interface()
+		}
+
+		values = append(values, value)
+	}
+
+	return encodeCursor(values, backward)
+}
+
+// builds condition selecting rows after (or before, for backward cursors) the cursor position:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func (result *CrudConfig[T, CtxType]) keysetCondition(keys []sortKey, cursor listCursor) (string, []any, error) {
+
+	if len(cursor.Values) != len(keys) {
+		return "", nil, ErrBadCursor
+	}
+
+	values := []any{}
+
+	for idx, it := range keys {
+
+		fieldInfo, ok := result.TypeDataModel.FieldByColumn(it.Column)
+		if !ok {
+			return "", nil, ErrBadCursor
+		}
+
+		typed := reflect.New(fieldInfo.NativeType)
+
+		err := json.Unmarshal(cursor.Values[idx], typed.Interface())
+		if err != nil {
+			return "", nil, ErrBadCursor
+		}
+
+		values = append(values, typed.Elem().Interface())
+	}
+
+	groups := []string{}
+	args := []any{}
+
+	for idx, it := range keys {
+
+		conds := []string{}
+
+		for prevIdx := 0; prevIdx < idx; prevIdx++ {
+			conds = append(conds, fmt.Sprintf("%s.%s = ?", result.tableName, keys[prevIdx].Column))
+			args = append(args, values[prevIdx])
+		}
+
+		op := ">"
+		if it.Desc != cursor.Backward {
+			op = "<"
+		}
+
+		conds = append(conds, fmt.Sprintf("%s.%s %s ?", result.tableName, it.Column, op))
+		args = append(args, values[idx])
+
+		groups = append(groups, "("+strings.Join(conds, " AND ")+")")
+	}
+
+	return "(" + strings.Join(groups, " OR ") + ")", args, nil
+}
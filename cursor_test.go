@@ -0,0 +1,66 @@
+package simpleapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCursorKeyset(t *testing.T) {
+
+	crud := mockEventsCrud()
+
+	keys := crud.cursorKeys([]sortKey{{Column: "label", Desc: true}})
+
+	if len(keys) != 2 || keys[1].Column != "id" || !keys[1].Desc {
+		t.Fatalf("primary key should be appended to sort keys: %#+v", keys)
+	}
+
+	encoded := crud.cursorFor(MockEvent{Id: 7, Label: "b"}, keys, false)
+
+	cursor, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("unable to decode cursor: %s", err.Error())
+	}
+
+	cond, args, err := crud.keysetCondition(keys, cursor)
+	if err != nil {
+		t.Fatalf("unable to build keyset condition: %s", err.Error())
+	}
+
+	expected := "((mock_events.label < ?) OR (mock_events.label = ? AND mock_events.id < ?))"
+	if cond != expected {
+		t.Errorf("unexpected keyset condition: %s", cond)
+	}
+
+	if len(args) != 3 || args[0] != "b" || args[2] != uint64(7) {
+		t.Errorf("unexpected keyset args: %#+v", args)
+	}
+
+	backCursor, _ := decodeCursor(crud.cursorFor(MockEvent{Id: 7, Label: "b"}, keys, true))
+	backCond, _, _ := crud.keysetCondition(keys, backCursor)
+
+	if backCond != "((mock_events.label > ?) OR (mock_events.label = ? AND mock_events.id > ?))" {
+		t.Errorf("unexpected backward keyset condition: %s", backCond)
+	}
+
+	if _, err := decodeCursor("not a cursor"); err == nil {
+		t.Errorf("malformed cursor should not be decoded")
+	}
+}
+
+func TestCursorNullableSort(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockNote{})
+
+	New(group, ServeMuxRouter(mux).Group("/notes"), MockNote{}).CursorPaging().Generate()
+
+	resp := mockRequest(t, mux, http.MethodGet, "/notes?sort=removed_at", "")
+	if resp.Code != 400 || resp.Body["column"] != "removed_at" {
+		t.Errorf("nullable sort key should be rejected in cursor mode: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/notes?sort=-text", "")
+	if resp.Code != 200 {
+		t.Errorf("non nullable sort key should be accepted: %d %v", resp.Code, resp.Body)
+	}
+}
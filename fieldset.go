@@ -0,0 +1,111 @@
+package simpleapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parses `id,title,created_at` list of output field names
+func parseFieldList(list string) []string {
+
+	result := []string{}
+
+	for _, it := range strings.Split(list, ",") {
+		it = strings.TrimSpace(it)
+		if it != "" {
+			result = append(result, it)
+		}
+	}
+
+	return result
+}
+
+// restricts dto output to given output field names. empty list means all fields
+func (r *RequestData) SelectFields(fields ...string) {
+
+	if len(fields) == 0 {
+		r.outFields = nil
+		return
+	}
+
+	r.outFields = map[string]bool{}
+
+	for _, it := range fields {
+		r.outFields[it] = true
+	}
+}
+
+func (r RequestData) SelectedFields() []string {
+
+	result := []string{}
+
+	for it := range r.outFields {
+		result = append(result, it)
+	}
+
+	return result
+}
+
+// checks if output field was requested, all fields are requested by default
+func (r RequestData) fieldSelected(outName string) bool {
+
+	if len(r.outFields) == 0 {
+		return true
+	}
+
+	return r.outFields[outName]
+}
+
+// removes keys added by extra dto methods that were not requested
+func (r RequestData) pruneDto(dto map[string]any) map[string]any {
+
+	if len(r.outFields) == 0 {
+		return dto
+	}
+
+	for k := range dto {
+		if !r.outFields[k] {
+			delete(dto, k)
+		}
+	}
+
+	return dto
+}
+
+// columns to select for requested fields, empty if whole row is needed.
+// primary key and sort columns are always selected
+func (result *CrudConfig[T, CtxType]) selectColumns(req RequestData, sortKeys []sortKey) []string {
+
+	if len(req.outFields) == 0 || result.TypeDataModel.OutExtraMethod {
+		return nil
+	}
+
+	used := map[string]bool{}
+	columns := []string{}
+
+	addColumn := func(column string) {
+		if used[column] || !result.dbColumns[column] {
+			return
+		}
+
+		used[column] = true
+		columns = append(columns, fmt.Sprintf("%s.%s", result.tableName, column))
+	}
+
+	addColumn(result.primaryIdDbName)
+
+	for _, it := range sortKeys {
+		addColumn(it.Column)
+	}
+
+	for _, declName := range result.TypeDataModel.Outable {
+
+		fieldInfo := result.TypeDataModel.Fields[declName]
+
+		if fieldInfo.Name != nil && req.fieldSelected(*fieldInfo.Name) {
+			addColumn(fieldInfo.TableColumnName)
+		}
+	}
+
+	return columns
+}
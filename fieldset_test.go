@@ -0,0 +1,28 @@
+package simpleapi
+
+import "testing"
+
+func TestSparseFieldset(t *testing.T) {
+
+	fields := GetFieldTags[MockAppContext, MockReport](MockReport{})
+
+	req := RequestData{}
+	req.SelectFields(parseFieldList(" id, notes ,title")...)
+
+	dto := fields.ToDto(MockReport{Id: 1, Title: "t", Notes: "n"}, req)
+
+	if len(dto) != 2 || dto["id"] != uint64(1) || dto["title"] != "t" {
+		t.Errorf("dto should contain only requested readable fields: %v", dto)
+	}
+
+	crud := mockEventsCrud()
+	crud.dbColumns = map[string]bool{"id": true, "label": true, "soft_deleted": true}
+
+	eventReq := RequestData{}
+	eventReq.SelectFields("label")
+
+	columns := crud.selectColumns(eventReq, nil)
+	if len(columns) != 2 || columns[0] != "mock_events.id" || columns[1] != "mock_events.label" {
+		t.Errorf("unexpected select columns: %v", columns)
+	}
+}
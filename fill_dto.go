@@ -1,6 +1,7 @@
 package simpleapi
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"math"
@@ -65,7 +66,7 @@ func ProcessFieldType(fieldInfo ApiTags, jsonFieldValue gjson.Result, req Reques
 
 	var dtoData any
 
-	req.log_format(" [%s] field detected typ : %s", *fieldInfo.Name, fieldTypeKind.String())
+	req.log_format(" [%s] field detected typ : %s", fieldInfo.TableColumnName, fieldTypeKind.String())
 
 	switch fieldTypeKind {
 	case reflect.Slice:
@@ -73,7 +74,7 @@ func ProcessFieldType(fieldInfo ApiTags, jsonFieldValue gjson.Result, req Reques
 		elementType := fieldType.Elem()
 
 		if elementType.Kind() != reflect.Uint64 {
-			req.log_format("[%s] field has unsupported array type : %s", *fieldInfo.Name, elementType)
+			req.log_format("[%s] field has unsupported array type : %s", fieldInfo.TableColumnName, elementType)
 		} else {
 
 			result := []uint64{}
@@ -200,7 +201,7 @@ func ProcessFieldType(fieldInfo ApiTags, jsonFieldValue gjson.Result, req Reques
 		dtoData = boolval
 	default:
 
-		req.log_format(" [%s] field defaulted while converting from input data (json.Value), typ: %s", *fieldInfo.Name, fieldInfo.NativeType)
+		req.log_format(" [%s] field defaulted while converting from input data (json.Value), typ: %s", fieldInfo.TableColumnName, fieldInfo.NativeType)
 
 		processor, hasProcessor := fieldTypeProcessors[fieldInfo.Typ]
 
@@ -218,3 +219,48 @@ func ProcessFieldType(fieldInfo ApiTags, jsonFieldValue gjson.Result, req Reques
 	return dtoData, err
 
 }
+
+// converts arbitrary value, eg authorized user id, to a field type and sets it
+func setFieldValue(obj any, declName string, fieldInfo ApiTags, value any, req RequestData) error {
+
+	field := reflect.Indirect(reflect.ValueOf(obj)).FieldByName(declName)
+
+	if !field.CanSet() {
+		return fmt.Errorf("field %s can't be set", declName)
+	}
+
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	// convert through json for simplicity of using force converting types methods
+	valj, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	isPtr := fieldInfo.NativeType.Kind() == reflect.Pointer
+
+	processedField := fieldInfo
+	if isPtr {
+		processedField.NativeType = fieldInfo.NativeType.Elem()
+	}
+
+	converted, err := ProcessFieldType(processedField, gjson.ParseBytes(valj), req)
+	if err != nil {
+		return err
+	}
+
+	convertedVal := reflect.ValueOf(converted)
+
+	if isPtr {
+		ptr := reflect.New(processedField.NativeType)
+		ptr.Elem().Set(convertedVal)
+		convertedVal = ptr
+	}
+
+	field.Set(convertedVal)
+
+	return nil
+}
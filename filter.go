@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/dot5enko/typed"
@@ -17,8 +18,8 @@ type complexFilter[CtxType any] struct {
 	filterData *HasManyConfig[CtxType]
 }
 
-func (c complexFilter[T]) RelFieldName(name string) string {
-	return fmt.Sprintf("%s.%s", c.filterData.RelTable, name)
+func (c complexFilter[T]) RelFieldName(alias string, name string) string {
+	return fmt.Sprintf("%s.%s", alias, name)
 }
 
 type filterData[CtxType any] struct {
@@ -26,11 +27,12 @@ type filterData[CtxType any] struct {
 	QueryPlaceholder string
 	Args             []any
 
-	ComplexFilters []complexFilter[CtxType]
-
 	Limit   int
 	Offset  int
 	PerPage int
+
+	// validated list ordering
+	Sort []sortKey
 }
 
 // func (filterData) Compile() (string, []any) {
@@ -42,11 +44,88 @@ var ErrNoAccess = fmt.Errorf("user doesn't have access")
 type ListQueryParams struct {
 	SortField           string `form:"sort_field"`
 	SortOrder           int    `form:"order"`
+	Sort                string `form:"sort"` // `-priority,created_at`, takes precedence over sort_field
 	Page                int    `form:"page"`
 	PerPage             int64  `form:"per_page"`
 	PredefinedQuery     string `form:"q"`
 	PredefinedQueryArgs string `form:"args"`
 	Filter              string `form:"filter"`
+	SkipCount           bool   `form:"skip_count"`
+	Cursor              string `form:"cursor"`
+	Fields              string `form:"fields"`
+}
+
+// requested ordering, `sort` param or legacy `sort_field` + `order`
+func (p ListQueryParams) sortKeys() []sortKey {
+
+	if p.Sort != "" {
+		return parseSortParam(p.Sort)
+	}
+
+	if p.SortField != "" {
+		return []sortKey{{
+			Column: p.SortField,
+			Desc:   p.SortOrder == -1,
+		}}
+	}
+
+	return []sortKey{}
+}
+
+func (p ListQueryParams) hasSort() bool {
+	return p.Sort != "" || p.SortField != ""
+}
+
+// parses `-priority,created_at`, leading minus means descending order
+func parseSortParam(sort string) []sortKey {
+
+	result := []sortKey{}
+
+	for _, it := range strings.Split(sort, ",") {
+
+		it = strings.TrimSpace(it)
+
+		desc := strings.HasPrefix(it, "-")
+		it = strings.TrimSpace(strings.TrimLeft(it, "+-"))
+
+		if it == "" {
+			continue
+		}
+
+		result = append(result, sortKey{
+			Column: it,
+			Desc:   desc,
+		})
+	}
+
+	return result
+}
+
+// drops sort keys over non sortable or non readable fields and repeated columns
+func validSortKeys(modelDataStruct FieldsMapping, keys []sortKey, userAuthData RequestData) []sortKey {
+
+	result := []sortKey{}
+	used := map[string]bool{}
+
+	for _, it := range keys {
+
+		_, canBeSorted := modelDataStruct.Filterable[it.Column]
+
+		if canBeSorted {
+			sortFieldInfo, _ := modelDataStruct.FieldByColumn(it.Column)
+			canBeSorted = sortFieldInfo.Readable(userAuthData)
+		}
+
+		if !canBeSorted || used[it.Column] {
+			userAuthData.log_format("sorting by %s is not allowed, skipped", it.Column)
+			continue
+		}
+
+		used[it.Column] = true
+		result = append(result, it)
+	}
+
+	return result
 }
 
 func processFilterValueToSqlCond(tableName string, filterValue any, userAuthData RequestData, filterFieldName string, fieldInfo ApiTags) (fQueryCond string, argProcessed any, err error) {
@@ -54,6 +133,12 @@ func processFilterValueToSqlCond(tableName string, filterValue any, userAuthData
 
 	tableColumName := fieldInfo.TableColumnName
 
+	fname := tableColumName
+
+	if tableName != "" {
+		fname = fmt.Sprintf("%s.%s", tableName, tableColumName)
+	}
+
 	if isMap {
 		opName, ok := mapVal["op"].(string)
 
@@ -65,12 +150,6 @@ func processFilterValueToSqlCond(tableName string, filterValue any, userAuthData
 				var argVal any
 				var errProcessingFilterVal error
 
-				fname := tableColumName
-
-				if tableName != "" {
-					fname = fmt.Sprintf("%s.%s", tableName, tableColumName)
-				}
-
 				fQueryCond, argVal = filterGenerator(fname, mapVal)
 
 				// convert back to gjson for simplicity of using force converting types methods
@@ -101,7 +180,7 @@ func processFilterValueToSqlCond(tableName string, filterValue any, userAuthData
 		// todo validate type
 		// expose type processor same as supported filters
 
-		fQueryCond = fmt.Sprintf("%s = ?", tableColumName)
+		fQueryCond = fmt.Sprintf("%s = ?", fname)
 		argProcessed = filterValue
 		return
 	}
@@ -109,6 +188,284 @@ func processFilterValueToSqlCond(tableName string, filterValue any, userAuthData
 	return
 }
 
+const (
+	filterAndKey = "$and"
+	filterOrKey  = "$or"
+	filterNotKey = "$not"
+
+	// max depth of nested filter groups
+	maxFilterDepth = 8
+)
+
+// compiles a user provided filter tree into sql conditions
+type filterCompiler[T any, CtxType any] struct {
+	crudConfig      *CrudConfig[T, CtxType]
+	modelDataStruct FieldsMapping
+	userAuthData    RequestData
+
+	// fields that are forced by access rules and compiled separately
+	skipFields map[string]bool
+
+	// used to give each has-many subquery its own table alias
+	complexFiltersCount int
+}
+
+func sortedFilterKeys(node HM) []string {
+
+	keys := make([]string, 0, len(node))
+
+	for k := range node {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// compiles a group of conditions joined with AND
+func (fc *filterCompiler[T, CtxType]) compileNode(node HM, depth int) (string, []any) {
+
+	parts := []string{}
+	args := []any{}
+
+	if depth > maxFilterDepth {
+		fc.userAuthData.log_format("filter is nested too deep, group skipped")
+		return "", args
+	}
+
+	for _, filterFieldName := range sortedFilterKeys(node) {
+
+		filterValue := node[filterFieldName]
+
+		var sqlPart string
+		var sqlArgs []any
+
+		switch filterFieldName {
+		case filterAndKey, filterOrKey:
+
+			items, ok := filterValue.([]any)
+			if !ok {
+				fc.userAuthData.log_format("%s group should be an array, skipped", filterFieldName)
+				continue
+			}
+
+			joiner := " AND "
+			if filterFieldName == filterOrKey {
+				joiner = " OR "
+			}
+
+			groupParts := []string{}
+
+			for _, it := range items {
+
+				itemNode, ok := it.(map[string]any)
+				if !ok {
+					fc.userAuthData.log_format("%s group item should be an object, skipped", filterFieldName)
+					continue
+				}
+
+				itemSql, itemArgs := fc.compileNode(itemNode, depth+1)
+				if itemSql != "" {
+					groupParts = append(groupParts, itemSql)
+					sqlArgs = append(sqlArgs, itemArgs...)
+				}
+			}
+
+			if len(groupParts) > 0 {
+				sqlPart = "(" + strings.Join(groupParts, joiner) + ")"
+			}
+
+		case filterNotKey:
+
+			itemNode, ok := filterValue.(map[string]any)
+			if !ok {
+				fc.userAuthData.log_format("%s should be an object, skipped", filterFieldName)
+				continue
+			}
+
+			itemSql, itemArgs := fc.compileNode(itemNode, depth+1)
+			if itemSql != "" {
+				sqlPart = "NOT (" + itemSql + ")"
+				sqlArgs = itemArgs
+			}
+
+		default:
+			sqlPart, sqlArgs = fc.compileField(filterFieldName, filterValue, depth)
+		}
+
+		if sqlPart != "" {
+			parts = append(parts, sqlPart)
+			args = append(args, sqlArgs...)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", args
+	}
+
+	if len(parts) == 1 || depth == 0 {
+		return strings.Join(parts, " AND "), args
+	}
+
+	return "(" + strings.Join(parts, " AND ") + ")", args
+}
+
+func (fc *filterCompiler[T, CtxType]) compileField(filterFieldName string, filterValue any, depth int) (string, []any) {
+
+	modelDataStruct := fc.modelDataStruct
+	userAuthData := fc.userAuthData
+
+	if fc.skipFields[filterFieldName] {
+		return "", nil
+	}
+
+	declaredFieldName, ok := modelDataStruct.ReverseFillFields[filterFieldName]
+
+	if !ok {
+
+		data, hasFilter := fc.crudConfig.HasManyFilter(filterFieldName)
+
+		if !hasFilter {
+			userAuthData.log_format("field %s is not fillable, skipped", filterFieldName)
+
+			// field is not fillable
+			return "", nil
+		}
+
+		userAuthData.log_format("field %s is one to many filter", filterFieldName)
+
+		return fc.compileHasMany(complexFilter[CtxType]{
+			filterData: data,
+			inputValue: filterValue,
+			fiedName:   filterFieldName,
+		})
+	} else {
+		userAuthData.log_format("field %s is filterable", filterFieldName)
+	}
+
+	fieldInfo := modelDataStruct.Fields[declaredFieldName]
+
+	// user can't filter over a field he can't read
+	if !fieldInfo.Readable(userAuthData) {
+		userAuthData.log_format("field %s is not readable, skipped", filterFieldName)
+		return "", nil
+	}
+
+	// allow only whitelisted fields
+	if !userAuthData.IsAdmin {
+		_, canBeFiltered := modelDataStruct.Filterable[filterFieldName]
+		if !canBeFiltered {
+			return "", nil
+		}
+	}
+
+	// if result.CrudGroup.Config.DisableFilter
+	if len(fc.crudConfig.disableFilterOverFields) > 0 {
+		_, disabled := fc.crudConfig.disableFilterOverFields[filterFieldName]
+		if disabled {
+
+			userAuthData.log_format("filter by %s is disabled by conf", filterFieldName)
+
+			return "", nil
+		}
+	}
+
+	sqlPart, sqlArg, filterProcessErr := processFilterValueToSqlCond(fc.crudConfig.tableName, filterValue, userAuthData, filterFieldName, fieldInfo)
+
+	if filterProcessErr != nil {
+		userAuthData.log_format("unable to process filter %s value: %s ", filterFieldName, filterProcessErr.Error())
+		return "", nil
+	}
+
+	if sqlPart == "" {
+		return "", nil
+	}
+
+	return sqlPart, []any{sqlArg}
+}
+
+// compiles a has-many filter into EXISTS subquery over relation table,
+// so any number of such filters could be combined without multiplying rows
+func (fc *filterCompiler[T, CtxType]) compileHasMany(it complexFilter[CtxType]) (sqlPart string, sqlArgs []any) {
+
+	userAuthData := fc.userAuthData
+
+	defer func() {
+		rec := recover()
+		if rec != nil {
+			userAuthData.log_format("unable to process complex filter for field `%s`: %s", it.fiedName, rec)
+
+			sqlPart = ""
+			sqlArgs = nil
+		}
+	}()
+
+	val := it.inputValue
+
+	if it.filterData.InputTransformer != nil {
+		userAuthData.log_format(" filter has input transformer, applying...")
+		val = it.filterData.InputTransformer(fc.crudConfig.App, val)
+	}
+
+	fc.complexFiltersCount += 1
+	alias := fmt.Sprintf("hm%d_%s", fc.complexFiltersCount, it.filterData.RelTable)
+
+	typ := reflect.TypeOf(uint64(0))
+	name := it.filterData.RelDestFieldName
+
+	fakeApiTags := ApiTags{
+		TableColumnName: it.RelFieldName(alias, name),
+		TypeKind:        reflect.Uint64,
+		NativeType:      typ,
+		Typ:             typ.Name(),
+		Fillable:        true,
+		FillName:        nil,
+		Name:            &name,
+	}
+
+	processedComplexFieldSql, complexArg, err := processFilterValueToSqlCond("", val, userAuthData, it.fiedName, fakeApiTags)
+	if err != nil {
+		userAuthData.log_format("unable to generate complex filter (%s) value :%s", it.fiedName, err.Error())
+		return "", nil
+	}
+
+	if processedComplexFieldSql == "" {
+		return "", nil
+	}
+
+	sqlPart = fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM %s AS %s WHERE %s = %s.%s AND %s)",
+		it.filterData.RelTable,
+		alias,
+		it.RelFieldName(alias, it.filterData.RelCurFieldName),
+		fc.crudConfig.tableName,
+		fc.crudConfig.objectIdField,
+		processedComplexFieldSql,
+	)
+
+	return sqlPart, []any{complexArg}
+}
+
+// compiles a condition enforced by access rules, bypassing readability checks
+func (fc *filterCompiler[T, CtxType]) compileForced(filterFieldName string, filterValue any) (string, []any) {
+
+	declaredFieldName := fc.modelDataStruct.ReverseFillFields[filterFieldName]
+	fieldInfo := fc.modelDataStruct.Fields[declaredFieldName]
+
+	sqlPart, sqlArg, err := processFilterValueToSqlCond(fc.crudConfig.tableName, filterValue, fc.userAuthData, filterFieldName, fieldInfo)
+	if err != nil || sqlPart == "" {
+		// should never happen, but never drop an access condition silently
+		panic(fmt.Sprintf("unable to compile forced filter for %s: %v", filterFieldName, err))
+	}
+
+	return sqlPart, []any{sqlArg}
+}
+
+// filters map could be a tree of conditions:
+// top level keys are joined with AND, `$and`/`$or` take an array of nested filters,
+// `$not` takes a nested filter. has-many filters could be used at any level.
+// soft delete and user reference rules are applied to the whole tree
 func prepareFilterData[T any, CtxType any](
 	filtersMap HM,
 	crudConfig *CrudConfig[T, CtxType],
@@ -117,38 +474,56 @@ func prepareFilterData[T any, CtxType any](
 	listQueryParams ListQueryParams,
 ) typed.Result[filterData[CtxType]] {
 
-	complexFilters := []complexFilter[CtxType]{}
+	compiler := &filterCompiler[T, CtxType]{
+		crudConfig:      crudConfig,
+		modelDataStruct: modelDataStruct,
+		userAuthData:    userAuthData,
+		skipFields:      map[string]bool{},
+	}
 
-	filtersSqlWithPlaceholders := ""
+	// conditions applied to the whole filter tree
+	forcedParts := []string{}
+	forcedArgs := []any{}
 
-	hasDisabledFields := len(crudConfig.disableFilterOverFields) > 0
+	addForced := func(fieldName string, value any) {
+		part, args := compiler.compileForced(fieldName, value)
 
-	parts := []string{}
-	filterArgs := []any{}
+		forcedParts = append(forcedParts, part)
+		forcedArgs = append(forcedArgs, args...)
 
-	softdeleteField := ""
-	keepSoftDeleted := false
+		compiler.skipFields[fieldName] = true
+	}
 
-	userBoundField := ""
-	keepUserBound := false
+	addForcedSql := func(fieldName string, part string, args []any) {
+		forcedParts = append(forcedParts, part)
+		forcedArgs = append(forcedArgs, args...)
+
+		compiler.skipFields[fieldName] = true
+	}
 
 	// filter soft deleted item
 	if modelDataStruct.SoftDeleteField.Has {
-		if !userAuthData.IsAdmin { // always hide softly removed items from userland, no exceptions
-			softdeleteField = modelDataStruct.SoftDeleteField.FillName
-			filtersMap[softdeleteField] = 0
-			keepSoftDeleted = true
+
+		softdeleteField := modelDataStruct.SoftDeleteField.FillName
+		softdeleteValue, removeFilterExists := filtersMap[softdeleteField]
+
+		if !userAuthData.IsAdmin || !removeFilterExists {
+			// always hide softly removed items from userland, no exceptions
+			// for admins hide removed elements by default
+			part, args := crudConfig.softDeleteCondition(false)
+			addForcedSql(softdeleteField, part, args)
 		} else {
 			// if admin request forcely wants to query `removed` data - no problem
-			_, removeFilterExists := filtersMap[modelDataStruct.SoftDeleteField.FillName]
-			if !removeFilterExists {
-				// hide removed elements by default
-				filtersMap[modelDataStruct.SoftDeleteField.FillName] = 0
-				keepSoftDeleted = true
-			}
+			_, isComplex := softdeleteValue.(map[string]any)
 
-			userAuthData.log_format(" softremoved `%s` set to `%v`", modelDataStruct.SoftDeleteField.FillName, filtersMap[modelDataStruct.SoftDeleteField.FillName])
+			// time columns can't be compared with a flag, so plain values select removed or present items
+			if modelDataStruct.SoftDeleteField.Kind != SoftDeleteFlag && !isComplex {
+				part, args := crudConfig.softDeleteCondition(isTruthy(softdeleteValue))
+				addForcedSql(softdeleteField, part, args)
+			}
 		}
+
+		userAuthData.log_format(" softremoved `%s` set to `%v`", softdeleteField, softdeleteValue)
 	}
 
 	// override user related fields to current auth user if its not an admin
@@ -156,7 +531,7 @@ func prepareFilterData[T any, CtxType any](
 	// each table/entity should have it own type for id ?
 	if modelDataStruct.UserReferenceField.Has {
 
-		userBoundField = modelDataStruct.UserReferenceField.FillName
+		userBoundField := modelDataStruct.UserReferenceField.FillName
 
 		skipUserField := false
 
@@ -182,89 +557,29 @@ func prepareFilterData[T any, CtxType any](
 			} else {
 				// now its working cause db_name == fill_name
 				// todo fix to use fll name
-				keepUserBound = true
-
-				filtersMap[userBoundField] = userAuthData.AuthorizedUserId
+				addForced(userBoundField, authId)
 			}
 
-			userAuthData.log_format(" user reference field `%s` set to `%v`", modelDataStruct.UserReferenceField.FillName, filtersMap[modelDataStruct.UserReferenceField.FillName])
+			userAuthData.log_format(" user reference field `%s` set to `%v`", userBoundField, authId)
 		}
 	}
 
-	for filterFieldName, filterValue := range filtersMap {
-
-		declaredFieldName, ok := modelDataStruct.ReverseFillFields[filterFieldName]
-
-		if !ok {
-
-			data, hasFilter := crudConfig.HasManyFilter(filterFieldName)
-
-			if !hasFilter {
-				userAuthData.log_format("field %s is not fillable, skipped", filterFieldName)
-			} else {
-				userAuthData.log_format("field %s is one to many filter, using filter data in next step", filterFieldName)
-
-				complexFilters = append(complexFilters, complexFilter[CtxType]{
-					filterData: data,
-					inputValue: filterValue,
-					fiedName:   filterFieldName,
-				})
-			}
-			// field is not fillable
-			continue
-		} else {
-			userAuthData.log_format("field %s is filterable", filterFieldName)
-		}
-
-		fieldInfo := modelDataStruct.Fields[declaredFieldName]
+	treeSql, treeArgs := compiler.compileNode(filtersMap, 0)
 
-		// allow only whitelisted fields
-		if !userAuthData.IsAdmin {
+	parts := forcedParts
+	filterArgs := forcedArgs
 
-			cont := false
-
-			if softdeleteField == filterFieldName {
-				if keepSoftDeleted {
-					cont = true
-				}
-			}
-
-			if userBoundField == filterFieldName {
-				if keepUserBound {
-					cont = true
-				}
-			}
-
-			if !cont {
-				_, canBeFiltered := modelDataStruct.Filterable[filterFieldName]
-				if !canBeFiltered {
-					continue
-				}
-			}
+	if treeSql != "" {
+		if len(parts) > 0 {
+			// keep OR groups from escaping forced conditions
+			treeSql = "(" + treeSql + ")"
 		}
 
-		// if result.CrudGroup.Config.DisableFilter
-		if hasDisabledFields {
-			_, disabled := crudConfig.disableFilterOverFields[filterFieldName]
-			if disabled {
-
-				userAuthData.log_format("filter by %s is disabled by conf", filterFieldName)
-
-				continue
-			}
-		}
-
-		sqlPart, sqlArg, filterProcessErr := processFilterValueToSqlCond(crudConfig.tableName, filterValue, userAuthData, filterFieldName, fieldInfo)
-
-		if filterProcessErr != nil {
-			userAuthData.log_format("unable to process filter %s value: %s ", filterFieldName, filterProcessErr.Error())
-		} else {
-			parts = append(parts, sqlPart)
-			filterArgs = append(filterArgs, sqlArg)
-		}
+		parts = append(parts, treeSql)
+		filterArgs = append(filterArgs, treeArgs...)
 	}
 
-	filtersSqlWithPlaceholders = strings.Join(parts, " AND ")
+	filtersSqlWithPlaceholders := strings.Join(parts, " AND ")
 
 	userAuthData.log(func(logger *log.Logger) {
 		logger.Print("filter SQL:")
@@ -284,14 +599,7 @@ func prepareFilterData[T any, CtxType any](
 	limitVal := int(perPageVal)
 	offsetVal := (curPage - 1) * int(perPageVal)
 
-	// check sorting field
-	if listQueryParams.SortField != "" {
-		_, canBeSorted := modelDataStruct.Filterable[listQueryParams.SortField]
-
-		if !canBeSorted {
-			listQueryParams.SortField = ""
-		}
-	}
+	sortKeys := validSortKeys(modelDataStruct, listQueryParams.sortKeys(), userAuthData)
 
 	return typed.ResultOk(filterData[CtxType]{
 		QueryPlaceholder: filtersSqlWithPlaceholders,
@@ -299,7 +607,7 @@ func prepareFilterData[T any, CtxType any](
 		Limit:            limitVal,
 		Offset:           offsetVal,
 		PerPage:          int(perPageVal),
-		ComplexFilters:   complexFilters,
+		Sort:             sortKeys,
 		_filter:          filtersMap,
 	})
 }
@@ -1,7 +1,132 @@
 package simpleapi
 
-import "testing"
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func mockEventsCrud() *CrudConfig[MockEvent, MockAppContext] {
+
+	fields := GetFieldTags[MockAppContext, MockEvent](MockEvent{})
+
+	return &CrudConfig[MockEvent, MockAppContext]{
+		TypeDataModel:   fields,
+		tableName:       "mock_events",
+		primaryIdDbName: "id",
+		objectIdField:   "id",
+		paging: PagingConfig{
+			PerPage: 30,
+		},
+	}
+}
+
+func compileMockFilter(t *testing.T, filter string, req RequestData) filterData[MockAppContext] {
+
+	crud := mockEventsCrud()
+
+	filtersMap := HM{}
+	if err := json.Unmarshal([]byte(filter), &filtersMap); err != nil {
+		t.Fatalf("bad filter json: %s", err.Error())
+	}
+
+	compiled := prepareFilterData[MockEvent, MockAppContext](filtersMap, crud, crud.TypeDataModel, req, ListQueryParams{})
+
+	return compiled.Unwrap()
+}
 
 func TestSoftdelete(t *testing.T) {
 
+	data := compileMockFilter(t, `{"$or":[{"label":"a"},{"_deleted":true}]}`, RequestData{})
+
+	expected := "mock_events.soft_deleted = ? AND ((mock_events.label = ?))"
+
+	if data.QueryPlaceholder != expected {
+		t.Errorf("soft delete condition should wrap the whole filter tree, got `%s`", data.QueryPlaceholder)
+	}
+
+	adminData := compileMockFilter(t, `{"_deleted":true}`, RequestData{IsAdmin: true})
+
+	if adminData.QueryPlaceholder != "mock_events.soft_deleted = ?" || adminData.Args[0] != true {
+		t.Errorf("admin should be able to query removed items, got `%s` %v", adminData.QueryPlaceholder, adminData.Args)
+	}
+}
+
+func TestTimeSoftdelete(t *testing.T) {
+
+	fields := GetFieldTags[MockAppContext, MockNote](MockNote{})
+
+	if fields.SoftDeleteField.Kind != SoftDeleteTime || !fields.SoftDeleteField.DeletedBy.Has {
+		t.Fatalf("time soft delete field not detected: %+v", fields.SoftDeleteField)
+	}
+
+	if fields.Fields["RemovedBy"].Fillable {
+		t.Errorf("deletedby field should not be fillable")
+	}
+
+	crud := &CrudConfig[MockNote, MockAppContext]{
+		TypeDataModel:   fields,
+		tableName:       "mock_notes",
+		primaryIdDbName: "id",
+		objectIdField:   "id",
+		paging: PagingConfig{
+			PerPage: 30,
+		},
+	}
+
+	data := prepareFilterData[MockNote, MockAppContext](HM{"removed_at": 1}, crud, fields, RequestData{AuthorizedUserId: 1}, ListQueryParams{}).Unwrap()
+
+	if data.QueryPlaceholder != "mock_notes.removed_at IS NULL" {
+		t.Errorf("removed notes should be hidden from users, got `%s`", data.QueryPlaceholder)
+	}
+
+	trash := prepareFilterData[MockNote, MockAppContext](crud.trashFilter(), crud, fields, RequestData{IsAdmin: true}, ListQueryParams{}).Unwrap()
+
+	if trash.QueryPlaceholder != "mock_notes.removed_at IS NOT NULL" {
+		t.Errorf("trash should select removed notes, got `%s`", trash.QueryPlaceholder)
+	}
+
+	note := MockNote{Id: 1}
+
+	columns, err := crud.stampSoftDelete(&note, true, RequestData{AuthorizedUserId: "7"})
+	if err != nil {
+		t.Fatalf("unable to stamp: %s", err.Error())
+	}
+
+	if note.RemovedAt == nil || note.RemovedBy != 7 || len(columns) != 2 {
+		t.Errorf("removal time and user should be stamped, got %+v %v", note, columns)
+	}
+
+	crud.stampSoftDelete(&note, false, RequestData{})
+
+	if note.RemovedAt != nil || note.RemovedBy != 0 {
+		t.Errorf("restore should reset stamps, got %+v", note)
+	}
+}
+
+func TestUnsupportedSoftdeleteType(t *testing.T) {
+
+	type mockStatus struct {
+		Id     uint64
+		Status string `simpleapi:"softdelete"`
+	}
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "soft delete") {
+			t.Errorf("string soft delete field should be rejected on setup, got %v", msg)
+		}
+	}()
+
+	app := NewAppContext(&MockAppContext{})
+	app.SetObjectsMapping(map[string]FieldsMapping{
+		GetObjectType(mockStatus{}): GetFieldTags[MockAppContext](mockStatus{}),
+	})
+
+	group := NewCrudGroup(*app, CrudGroupConfig[MockAppContext]{
+		ObjectIdFieldName: "id",
+	})
+
+	New(group, ServeMuxRouter(http.NewServeMux()), mockStatus{})
 }
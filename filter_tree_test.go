@@ -0,0 +1,83 @@
+package simpleapi
+
+import (
+	"testing"
+)
+
+func TestFilterTree(t *testing.T) {
+
+	data := compileMockFilter(t, `{
+		"id": {"op": "gt", "v": 10},
+		"$or": [
+			{"label": "open"},
+			{"$and": [{"label": "closed"}, {"id": {"op": "lt", "v": 100}}]}
+		],
+		"$not": {"label": "hidden"}
+	}`, RequestData{IsAdmin: true})
+
+	// keys are compiled in sorted order
+	expected := "mock_events.soft_deleted = ? AND (" +
+		"NOT (mock_events.label = ?) AND " +
+		"(mock_events.label = ? OR (mock_events.label = ? AND mock_events.id < ?)) AND " +
+		"mock_events.id > ?)"
+
+	if data.QueryPlaceholder != expected {
+		t.Errorf("unexpected filter sql:\n%s\nwant:\n%s", data.QueryPlaceholder, expected)
+	}
+
+	if len(data.Args) != 6 {
+		t.Errorf("unexpected args count: %v", data.Args)
+	}
+}
+
+func TestMultipleHasManyFilters(t *testing.T) {
+
+	crud := mockEventsCrud().
+		FieldFilter("tags", "event_tags", "tag_id", "event_id", nil).
+		FieldFilter("categories", "event_categories", "category_id", "event_id", nil)
+
+	filtersMap := HM{
+		"tags":       map[string]any{"op": "in", "v": []any{1, 2}},
+		"categories": 5,
+	}
+
+	compiled := prepareFilterData[MockEvent, MockAppContext](filtersMap, crud, crud.TypeDataModel, RequestData{IsAdmin: true}, ListQueryParams{})
+	data := compiled.Unwrap()
+
+	expected := "mock_events.soft_deleted = ? AND (" +
+		"EXISTS (SELECT 1 FROM event_categories AS hm1_event_categories WHERE hm1_event_categories.event_id = mock_events.id AND hm1_event_categories.category_id = ?) AND " +
+		"EXISTS (SELECT 1 FROM event_tags AS hm2_event_tags WHERE hm2_event_tags.event_id = mock_events.id AND hm2_event_tags.tag_id IN ?))"
+
+	if data.QueryPlaceholder != expected {
+		t.Errorf("unexpected filter sql:\n%s\nwant:\n%s", data.QueryPlaceholder, expected)
+	}
+
+	if len(data.Args) != 3 {
+		t.Errorf("unexpected args: %v", data.Args)
+	}
+}
+
+func TestMultiColumnSort(t *testing.T) {
+
+	fields := GetFieldTags[MockAppContext, MockEvent](MockEvent{})
+
+	params := ListQueryParams{
+		Sort:      "-label, id,unknown,-label",
+		SortField: "id",
+	}
+
+	keys := validSortKeys(fields, params.sortKeys(), RequestData{})
+
+	if len(keys) != 2 || keys[0] != (sortKey{Column: "label", Desc: true}) || keys[1] != (sortKey{Column: "id"}) {
+		t.Errorf("unexpected sort keys: %#+v", keys)
+	}
+
+	if orderClause("mock_events", keys, false) != "mock_events.label DESC, mock_events.id ASC" {
+		t.Errorf("unexpected order clause: %s", orderClause("mock_events", keys, false))
+	}
+
+	legacy := ListQueryParams{SortField: "label", SortOrder: -1}.sortKeys()
+	if len(legacy) != 1 || !legacy[0].Desc {
+		t.Errorf("legacy sort params are not supported: %#+v", legacy)
+	}
+}
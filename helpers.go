@@ -5,10 +5,9 @@ import (
 	"strconv"
 
 	"github.com/dot5enko/typed"
-	"github.com/gin-gonic/gin"
 )
 
-func GetObjFromContext[T any](ctx *gin.Context, name string) typed.Result[T] {
+func GetObjFromContext[T any](ctx Request, name string) typed.Result[T] {
 
 	user, isOk := ctx.Get(name)
 	if isOk {
@@ -25,7 +24,7 @@ func GetObjFromContext[T any](ctx *gin.Context, name string) typed.Result[T] {
 
 }
 
-func MustGetObjectFromContext[T any](ctx *gin.Context, name string) T {
+func MustGetObjectFromContext[T any](ctx Request, name string) T {
 
 	if ctx == nil {
 		panic("ctx is null, can't get anything of it")
@@ -39,7 +38,7 @@ func MustGetObjectFromContext[T any](ctx *gin.Context, name string) T {
 	}
 }
 
-func GetArgUint(ctx *gin.Context, name string) uint64 {
+func GetArgUint(ctx Request, name string) uint64 {
 	paramIdStr := ctx.Param(name)
 	paramId, err := strconv.ParseUint(paramIdStr, 10, 64)
 	if err != nil {
@@ -49,7 +48,7 @@ func GetArgUint(ctx *gin.Context, name string) uint64 {
 	}
 }
 
-func GetUserId(ctx *gin.Context) uint64 {
+func GetUserId(ctx Request) uint64 {
 
 	userObject := MustGetObjectFromContext[any](ctx, "user")
 
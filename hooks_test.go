@@ -0,0 +1,199 @@
+package simpleapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type mockHooked struct {
+	calls []string
+	panic bool
+}
+
+func (m *mockHooked) BeforeEntityCreate(ctx *AppContext[MockAppContext]) error {
+	m.calls = append(m.calls, "before_create")
+
+	if m.panic {
+		panic("boom")
+	}
+	return nil
+}
+
+func (m *mockHooked) BeforeUpdate(ctx *AppContext[MockAppContext]) error {
+	m.calls = append(m.calls, "before_update")
+	return nil
+}
+
+func (m *mockHooked) BeforeEntityDelete(ctx *AppContext[MockAppContext]) error {
+	return errors.New("protected")
+}
+
+func TestEntityHooks(t *testing.T) {
+
+	app := NewAppContext(&MockAppContext{})
+
+	obj := &mockHooked{}
+
+	if err := beforeEntityHook(obj, app, OperationCreate); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(obj.calls) != 1 || obj.calls[0] != "before_create" {
+		t.Errorf("create should invoke create hook only, got %v", obj.calls)
+	}
+
+	if err := beforeEntityHook(obj, app, OperationDelete); err == nil || err.Error() != "protected" {
+		t.Errorf("delete hook should veto removal, got %v", err)
+	}
+
+	obj.panic = true
+
+	err := beforeEntityHook(obj, app, OperationCreate)
+	if !errors.Is(err, ErrHookPanic) {
+		t.Errorf("hook panic should be turned into an error, got %v", err)
+	}
+}
+
+func TestDeleteHooks(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockAccount{})
+
+	mode := ""
+	calls := []string{}
+
+	// rows visible inside removal transaction
+	visible := func(appctx *AppContext[MockAppContext]) int64 {
+		cnt := int64(0)
+		appctx.Db.Raw().Model(&MockAccount{}).Count(&cnt)
+		return cnt
+	}
+
+	New(group, ServeMuxRouter(mux).Group("/accounts"), MockAccount{}).
+		OnBeforeDelete(func(crudContext CrudContext[MockAccount, MockAppContext], obj *MockAccount) error {
+			calls = append(calls, fmt.Sprintf("before:%d", visible(crudContext.App)))
+
+			switch mode {
+			case "panic":
+				panic("boom")
+			case "reject":
+				return errors.New("protected")
+			}
+			return nil
+		}).
+		OnAfterDelete(func(appctx *AppContext[MockAppContext], obj *MockAccount) error {
+			calls = append(calls, fmt.Sprintf("after:%d", visible(appctx)))
+
+			if mode == "reject_after" {
+				return errors.New("too late")
+			}
+			return nil
+		}).
+		Generate()
+
+	app := group.Ctx
+
+	if err := app.Db.Create(&MockAccount{Email: "a@test.com", Age: 20}); err != nil {
+		t.Fatalf("unable to create account: %s", err.Error())
+	}
+
+	for _, it := range []string{"reject", "reject_after", "panic"} {
+
+		mode = it
+		calls = nil
+
+		resp := mockRequest(t, mux, http.MethodDelete, "/accounts/1", "")
+		if resp.Code != 409 || countRows[MockAccount](t, app) != 1 {
+			t.Errorf("%s: removal should be rejected and rolled back: %d %v", it, resp.Code, resp.Body)
+		}
+	}
+
+	mode = ""
+	calls = nil
+
+	resp := mockRequest(t, mux, http.MethodDelete, "/accounts/1", "")
+	if resp.Code != 200 || countRows[MockAccount](t, app) != 0 {
+		t.Fatalf("removal failed: %d %v", resp.Code, resp.Body)
+	}
+
+	// before hook sees the row, after hook runs in the same transaction once it is gone
+	if strings.Join(calls, ",") != "before:1,after:0" {
+		t.Errorf("unexpected hooks order: %v", calls)
+	}
+}
+
+type mockCommitted struct {
+	Id    uint64
+	Label string
+
+	commits []EntityOperation `gorm:"-"`
+	found   int64             `gorm:"-"`
+}
+
+func (m *mockCommitted) AfterEntityCommit(ctx *AppContext[MockAppContext], op EntityOperation) {
+	m.commits = append(m.commits, op)
+
+	// detached ctx sees committed data and could start its own transaction
+	ctx.DbTransaction(func(c AppContext[MockAppContext]) error {
+		return c.Db.Raw().Model(&mockCommitted{}).Count(&m.found).Error
+	})
+}
+
+func TestAfterCommitCallbacks(t *testing.T) {
+
+	app := mockDbApp(t, &mockCommitted{})
+
+	calls := []string{}
+
+	// interleaved transactions keep their own callbacks
+	interleaved := func(outerErr error) error {
+		return app.DbTransaction(func(outer AppContext[MockAppContext]) error {
+
+			outer.OnCommit(func() { calls = append(calls, "outer") })
+
+			app.DbTransaction(func(inner AppContext[MockAppContext]) error {
+				inner.OnCommit(func() { calls = append(calls, "inner") })
+				return nil
+			})
+
+			return outerErr
+		})
+	}
+
+	if err := interleaved(nil); err != nil || strings.Join(calls, ",") != "inner,outer" {
+		t.Errorf("each transaction should run its own callbacks: %v", calls)
+	}
+
+	calls = nil
+
+	if err := interleaved(errors.New("rollback")); err == nil || strings.Join(calls, ",") != "inner" {
+		t.Errorf("rolled back transaction should not run callbacks: %v", calls)
+	}
+
+	calls = nil
+
+	app.OnCommit(func() { calls = append(calls, "now") })
+	if strings.Join(calls, ",") != "now" {
+		t.Errorf("callback outside of transaction should run immediately: %v", calls)
+	}
+
+	obj := &mockCommitted{Label: "test"}
+
+	err := app.DbTransaction(func(c AppContext[MockAppContext]) error {
+
+		if err := c.Db.Create(obj); err != nil {
+			return err
+		}
+
+		if len(obj.commits) != 0 {
+			t.Errorf("commit hook invoked before commit")
+		}
+		return nil
+	})
+
+	if err != nil || len(obj.commits) != 1 || obj.commits[0] != OperationCreate || obj.found != 1 {
+		t.Errorf("commit hook should run once after commit: %v %v found %d", err, obj.commits, obj.found)
+	}
+}
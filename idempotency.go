@@ -0,0 +1,169 @@
+package simpleapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// stored response of a write request made with an Idempotency-Key header
+type IdempotencyRecord struct {
+	Id uint64
+
+	// hash of key scoped by crud table and actor
+	Key string `gorm:"uniqueIndex;size:64"`
+
+	// hash of method, uri and body, same key could not be reused for another request
+	RequestHash string `gorm:"size:64"`
+
+	// 0 while request is in progress
+	Code int
+	Body []byte
+
+	CreatedAt time.Time
+}
+
+func (IdempotencyRecord) TableName() string {
+	return "simpleapi_idempotency"
+}
+
+// Idempotent makes write endpoints honour Idempotency-Key header:
+// a retry with the same key within the window returns the original response.
+// without automigrate IdempotencyRecord table should be created beforehand
+func (it *CrudConfig[T, CtxType]) Idempotent(window time.Duration) *CrudConfig[T, CtxType] {
+
+	if it.App.Db.automigrate {
+		err := it.App.Db.Raw().AutoMigrate(&IdempotencyRecord{})
+		if err != nil {
+			panic(fmt.Sprintf("unable to migrate idempotency table: %s", err.Error()))
+		}
+	} else if !it.App.Db.Raw().Migrator().HasTable(&IdempotencyRecord{}) {
+		panic(fmt.Sprintf("idempotency table %s does not exist, migrate IdempotencyRecord first", IdempotencyRecord{}.TableName()))
+	}
+
+	it.idempotencyWindow = window
+
+	return it
+}
+
+func hashParts(parts ...[]byte) string {
+
+	h := sha256.New()
+
+	for _, it := range parts {
+		h.Write(it)
+		// separator, so parts could not be shifted
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// checkPermission runs first, so requests without write access never store or replay a response
+func (result *CrudConfig[T, CtxType]) idempotencyMiddleware(checkPermission HandlerFunc) HandlerFunc {
+
+	return func(ctx Request) {
+
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+
+		if result.idempotencyWindow <= 0 || key == "" || ctx.HttpRequest().Method == http.MethodGet {
+			return
+		}
+
+		checkPermission(ctx)
+		if ctx.IsAborted() {
+			return
+		}
+
+		// body could be read again by handlers
+		data, err := ctx.Body()
+		if err != nil {
+			ctx.AbortWithStatusJSON(400, HM{
+				"msg": "unable to read request body",
+			})
+			return
+		}
+
+		reqData := result.RequestData(ctx)
+
+		scopedKey := hashParts([]byte(result.tableName), []byte(fmt.Sprintf("%v", reqData.AuthorizedUserId)), []byte(key))
+		requestHash := hashParts([]byte(ctx.HttpRequest().Method), []byte(ctx.HttpRequest().URL.RequestURI()), data)
+
+		db := result.App.Db.Raw()
+
+		// expired keys could be used again
+		db.Where(&IdempotencyRecord{Key: scopedKey}).Where("created_at < ?", time.Now().Add(-result.idempotencyWindow)).Delete(&IdempotencyRecord{})
+
+		record := IdempotencyRecord{
+			Key:         scopedKey,
+			RequestHash: requestHash,
+		}
+
+		// unique key rejects concurrent retries while the first request is in progress
+		insertErr := db.Create(&record).Error
+
+		if insertErr != nil {
+
+			existing := IdempotencyRecord{}
+
+			findErr := db.Where(&IdempotencyRecord{Key: scopedKey}).First(&existing).Error
+			if findErr != nil {
+				ctx.AbortWithStatusJSON(500, HM{
+					"msg": "unable to check idempotency key",
+				})
+				return
+			}
+
+			if existing.RequestHash != requestHash {
+				ctx.AbortWithStatusJSON(422, HM{
+					"msg": "idempotency key is already used for another request",
+				})
+				return
+			}
+
+			if existing.Code == 0 {
+				ctx.AbortWithStatusJSON(409, HM{
+					"msg": "request with this idempotency key is in progress",
+				})
+				return
+			}
+
+			reqData.log_format(" replaying response for idempotency key")
+
+			ctx.Header("Idempotent-Replayed", "true")
+			ctx.Data(existing.Code, "application/json; charset=utf-8", existing.Body)
+			ctx.Abort()
+			return
+		}
+
+		body := ctx.Record()
+
+		// otherwise every retry is rejected as in progress
+		defer func() {
+			rec := recover()
+			if rec != nil {
+				db.Delete(&record)
+				panic(rec)
+			}
+		}()
+
+		ctx.Next()
+
+		code := ctx.Status()
+
+		// failed requests could be retried
+		if code >= 500 {
+			db.Delete(&record)
+			return
+		}
+
+		db.Model(&record).Updates(IdempotencyRecord{
+			Code: code,
+			Body: body.Bytes(),
+		})
+	}
+}
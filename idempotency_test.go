@@ -0,0 +1,102 @@
+package simpleapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIdempotency(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockAccount{})
+
+	var readonly HasPermissionChecker[MockAppContext] = func(req Request, ctx *AppContext[MockAppContext]) bool {
+		return req.GetHeader("X-Readonly") == ""
+	}
+	group.Config.WritePermission = &readonly
+
+	New(group, ServeMuxRouter(mux).Group("/accounts"), MockAccount{}).
+		Idempotent(time.Hour).
+		UseBeforeCreate(func(ctx Request) {
+			if ctx.GetHeader("X-Panic") != "" {
+				panic("boom")
+			}
+		}).
+		OnAfterCreate(func(appctx *AppContext[MockAppContext], obj *MockAccount) error {
+			if obj.Nickname == "failing" {
+				return errors.New("rejected")
+			}
+			return nil
+		}).
+		Generate()
+
+	app := group.Ctx
+
+	body := func(nick string) string {
+		return fmt.Sprintf(`{"email": "a@test.com", "nick": %q, "age": 20}`, nick)
+	}
+
+	create := func(key string, nick string, headers ...string) mockResponse {
+		return mockRequest(t, mux, http.MethodPost, "/accounts", body(nick), append(headers, IdempotencyKeyHeader+": "+key)...)
+	}
+
+	first := create("k1", "alpha")
+	if first.Code != 200 || first.Header.Get("Idempotent-Replayed") != "" {
+		t.Fatalf("unable to create account: %d %v", first.Code, first.Body)
+	}
+
+	replayed := create("k1", "alpha")
+	if replayed.Code != 200 || replayed.Header.Get("Idempotent-Replayed") != "true" || fmt.Sprint(replayed.Body) != fmt.Sprint(first.Body) {
+		t.Errorf("retry should replay original response: %d %v", replayed.Code, replayed.Body)
+	}
+
+	if countRows[MockAccount](t, app) != 1 {
+		t.Errorf("retry should not create another object")
+	}
+
+	resp := create("k1", "beta")
+	if resp.Code != 422 {
+		t.Errorf("key reused for another request should be rejected: %d %v", resp.Code, resp.Body)
+	}
+
+	// first request with the key is not finished yet
+	app.Db.Raw().Create(&IdempotencyRecord{
+		Key:         hashParts([]byte("mock_accounts"), []byte("<nil>"), []byte("k2")),
+		RequestHash: hashParts([]byte(http.MethodPost), []byte("/accounts"), []byte(body("alpha"))),
+	})
+
+	resp = create("k2", "alpha")
+	if resp.Code != 409 {
+		t.Errorf("request in progress should be rejected: %d %v", resp.Code, resp.Body)
+	}
+
+	// failed requests are not stored, so they could be retried
+	for i := 0; i < 2; i++ {
+		resp = create("k3", "failing")
+		if resp.Code != 500 || resp.Header.Get("Idempotent-Replayed") != "" {
+			t.Errorf("5xx response should not be replayed: %d %v", resp.Code, resp.Body)
+		}
+	}
+
+	resp = create("k4", "alpha", "X-Readonly: 1")
+	if resp.Code != 403 || countRows[IdempotencyRecord](t, app) != 2 {
+		t.Errorf("request without write permission should not store a key: %d %v", resp.Code, resp.Body)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("handler panic is expected")
+			}
+		}()
+
+		create("k5", "alpha", "X-Panic: 1")
+	}()
+
+	resp = create("k5", "gamma")
+	if resp.Code != 200 || countRows[MockAccount](t, app) != 2 {
+		t.Errorf("key of panicked request should be released: %d %v", resp.Code, resp.Body)
+	}
+}
@@ -0,0 +1,322 @@
+package simpleapi
+
+import (
+	"encoding"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const JsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+type SchemaVariant string
+
+const (
+	// body accepted when creating an object, required rules apply
+	SchemaCreate SchemaVariant = "create"
+	// body accepted by PATCH, every field is optional
+	SchemaUpdate SchemaVariant = "update"
+	// object dto as returned by api
+	SchemaOutput SchemaVariant = "output"
+)
+
+var SchemaVariants = []SchemaVariant{SchemaCreate, SchemaUpdate, SchemaOutput}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+)
+
+// json representation of a go type, as produced by ToDto and accepted by FillEntityFromDto
+func typeSchema(typ reflect.Type) HM {
+
+	switch typ {
+	case timeType:
+		return HM{"type": "integer", "format": "unix-time"}
+	case gormDeletedType:
+		return HM{"type": []string{"string", "null"}, "format": "date-time"}
+	case uuidType:
+		return HM{"type": "string", "format": "uuid"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		schema := typeSchema(typ.Elem())
+
+		typName, ok := schema["type"].(string)
+		if ok {
+			schema["type"] = []string{typName, "null"}
+		}
+
+		return schema
+	case reflect.Bool:
+		return HM{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return HM{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return HM{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return HM{"type": "number"}
+	case reflect.String:
+		return HM{"type": "string"}
+	}
+
+	if typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType) {
+		return HM{"type": "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return HM{"type": "string", "contentEncoding": "base64"}
+		}
+		return HM{"type": "array", "items": typeSchema(typ.Elem())}
+	default:
+		return HM{"type": "object"}
+	}
+}
+
+// property schema of a model field with permission annotations
+func fieldSchema(fieldInfo ApiTags) HM {
+
+	schema := typeSchema(fieldInfo.NativeType)
+
+	if fieldInfo.AdminOnly {
+		schema["x-simpleapi-admin-only"] = true
+	}
+
+	if role := roleDeclString(fieldInfo.ReadRole, fieldInfo.ReadRoleName); role != "" {
+		schema["x-simpleapi-read-role"] = role
+	}
+
+	if role := roleDeclString(fieldInfo.WriteRole, fieldInfo.WriteRoleName); role != "" {
+		schema["x-simpleapi-write-role"] = role
+	}
+
+	return schema
+}
+
+func hasRule(fieldInfo ApiTags, name string) bool {
+	for _, it := range fieldInfo.Rules {
+		if it.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// enum value of a `oneof` rule in the field json type
+func enumValue(typ reflect.Type, value string) any {
+
+	switch reflect.Indirect(reflect.New(typ)).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			return parsed
+		}
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return parsed
+		}
+	}
+
+	return value
+}
+
+// adds constraints of builtin `validate` rules, custom rules are listed by name
+func applyRules(schema HM, fieldInfo ApiTags) {
+
+	custom := []string{}
+
+	kind := fieldInfo.NativeType.Kind()
+
+	for _, rule := range fieldInfo.Rules {
+
+		switch rule.Name {
+		case "required":
+			// listed in object required properties
+		case "email":
+			schema["format"] = "email"
+		case "regex":
+			schema["pattern"] = rule.Arg
+		case "oneof":
+			values := []any{}
+			for _, it := range strings.Fields(rule.Arg) {
+				values = append(values, enumValue(fieldInfo.NativeType, it))
+			}
+			schema["enum"] = values
+		case "unique":
+			schema["x-simpleapi-unique"] = true
+		case "min", "max":
+			bound, err := strconv.ParseFloat(rule.Arg, 64)
+			if err != nil {
+				continue
+			}
+
+			minKey, maxKey := "minimum", "maximum"
+
+			switch kind {
+			case reflect.String:
+				minKey, maxKey = "minLength", "maxLength"
+			case reflect.Slice, reflect.Array:
+				minKey, maxKey = "minItems", "maxItems"
+			case reflect.Map:
+				minKey, maxKey = "minProperties", "maxProperties"
+			}
+
+			if rule.Name == "max" {
+				schema[maxKey] = bound
+			} else {
+				schema[minKey] = bound
+			}
+		default:
+			custom = append(custom, rule.Name)
+		}
+	}
+
+	if len(custom) > 0 {
+		schema["x-simpleapi-rules"] = custom
+	}
+}
+
+// object schema of a model variant, without dialect declaration
+func modelSchema(m FieldsMapping, variant SchemaVariant) HM {
+
+	props := HM{}
+	required := []string{}
+
+	for declName, fieldInfo := range m.Fields {
+
+		if fieldInfo.Internal || fieldInfo.DeclError {
+			continue
+		}
+
+		if variant == SchemaOutput {
+			if fieldInfo.Outable {
+				props[*fieldInfo.Name] = fieldSchema(fieldInfo)
+			}
+			continue
+		}
+
+		// the same list FillEntityFromDto iterates over
+		if !fieldInfo.Fillable || !contains(m.Fillable, declName) {
+			continue
+		}
+
+		schema := fieldSchema(fieldInfo)
+		applyRules(schema, fieldInfo)
+
+		props[*fieldInfo.FillName] = schema
+
+		if variant == SchemaCreate && hasRule(fieldInfo, "required") {
+			required = append(required, *fieldInfo.FillName)
+		}
+	}
+
+	result := HM{
+		"type":       "object",
+		"properties": props,
+	}
+
+	if len(required) > 0 {
+		sort.Strings(required)
+		result["required"] = required
+	}
+
+	return result
+}
+
+func contains(list []string, value string) bool {
+	for _, it := range list {
+		if it == value {
+			return true
+		}
+	}
+	return false
+}
+
+// short type name, without package path
+func schemaTypeName(m FieldsMapping) string {
+
+	name := m.TypeName
+
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	return name
+}
+
+// ModelJsonSchema builds a standalone JSON Schema document of a model variant
+func ModelJsonSchema(m FieldsMapping, variant SchemaVariant) HM {
+
+	schema := modelSchema(m, variant)
+
+	schema["$schema"] = JsonSchemaDialect
+	schema["title"] = schemaTypeName(m) + " " + string(variant)
+	schema["x-simpleapi-type"] = m.TypeName
+
+	return schema
+}
+
+// JsonSchemas returns schemas of every registered type: short type name -> variant -> schema
+func (c AppContext[T]) JsonSchemas() map[string]map[SchemaVariant]HM {
+
+	result := map[string]map[SchemaVariant]HM{}
+
+	for _, m := range c.RegisteredTypes() {
+
+		variants := map[SchemaVariant]HM{}
+
+		for _, variant := range SchemaVariants {
+			variants[variant] = ModelJsonSchema(m, variant)
+		}
+
+		result[schemaTypeName(m)] = variants
+	}
+
+	return result
+}
+
+// ServeJsonSchemas registers `/schemas` with all registered types and
+// `/schemas/:name` with variants of a single type
+func (g *CrudGroup[T]) ServeJsonSchemas(router Router) {
+
+	router.Handle(http.MethodGet, "/schemas", func(ctx Request) {
+		ctx.JSON(200, g.Ctx.JsonSchemas())
+	})
+
+	router.Handle(http.MethodGet, "/schemas/:name", func(ctx Request) {
+
+		variants, ok := g.Ctx.JsonSchemas()[ctx.Param("name")]
+		if !ok {
+			ctx.JSON(404, HM{
+				"msg": "unknown type",
+			})
+			return
+		}
+
+		variant := SchemaVariant(ctx.Query("variant"))
+		if variant != "" {
+
+			schema, ok := variants[variant]
+			if !ok {
+				ctx.JSON(400, HM{
+					"msg": "unknown schema variant",
+				})
+				return
+			}
+
+			ctx.JSON(200, schema)
+			return
+		}
+
+		ctx.JSON(200, variants)
+	})
+}
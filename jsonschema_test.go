@@ -0,0 +1,40 @@
+package simpleapi
+
+import "testing"
+
+func TestModelJsonSchema(t *testing.T) {
+
+	fields := GetFieldTags[MockAppContext](MockAccount{})
+
+	create := ModelJsonSchema(fields, SchemaCreate)
+
+	props := create["properties"].(HM)
+
+	nick := props["nick"].(HM)
+	if nick["minLength"] != float64(3) || nick["maxLength"] != float64(12) || nick["pattern"] != "^[a-z,]+$" {
+		t.Errorf("string rules are not converted: %v", nick)
+	}
+
+	age := props["age"].(HM)
+	if age["minimum"] != float64(18) || age["type"] != "integer" {
+		t.Errorf("number rules are not converted: %v", age)
+	}
+
+	plan := props["plan"].(HM)
+	if enum, ok := plan["enum"].([]any); !ok || len(enum) != 2 || enum[0] != "free" {
+		t.Errorf("oneof rule should become enum: %v", plan)
+	}
+
+	if required, _ := create["required"].([]string); len(required) != 1 || required[0] != "email" {
+		t.Errorf("unexpected required fields: %v", create["required"])
+	}
+
+	if _, ok := ModelJsonSchema(fields, SchemaUpdate)["required"]; ok {
+		t.Errorf("update schema should not require fields")
+	}
+
+	stamped := ModelJsonSchema(GetFieldTags[MockAppContext](MockTicket{}), SchemaCreate)["properties"].(HM)
+	if _, ok := stamped["author_id"]; ok {
+		t.Errorf("server stamped fields should not be accepted")
+	}
+}
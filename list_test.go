@@ -0,0 +1,65 @@
+package simpleapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestListHasMore(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockAccount{})
+
+	New(group, ServeMuxRouter(mux).Group("/accounts"), MockAccount{}).PerPage(2).Generate()
+
+	app := group.Ctx
+
+	list := func(query string) HM {
+		resp := mockRequest(t, mux, http.MethodGet, "/accounts?sort=id"+query, "")
+		if resp.Code != 200 {
+			t.Fatalf("list failed %d: %v", resp.Code, resp.Body)
+		}
+		return resp.Body
+	}
+
+	add := func(n int) {
+		for i := 0; i < n; i++ {
+			if err := app.Db.Create(&MockAccount{Email: "a@b.c", Age: 20}); err != nil {
+				t.Fatalf("unable to create account: %s", err.Error())
+			}
+		}
+	}
+
+	add(2)
+
+	// exactly per_page rows
+	page := list("")
+	if page["has_more"] != false || len(page["items"].([]any)) != 2 || page["total_items"] != float64(2) || page["pages"] != float64(1) {
+		t.Errorf("unexpected page at exactly per_page items: %v", page)
+	}
+
+	add(1)
+
+	// per_page+1 rows
+	page = list("")
+	if page["has_more"] != true || len(page["items"].([]any)) != 2 || page["pages"] != float64(2) {
+		t.Errorf("unexpected page at per_page+1 items: %v", page)
+	}
+
+	// last page
+	page = list("&page=2")
+	if page["has_more"] != false || len(page["items"].([]any)) != 1 {
+		t.Errorf("unexpected last page: %v", page)
+	}
+
+	// skip count
+	page = list("&skip_count=true")
+	if _, ok := page["total_items"]; ok || page["has_more"] != true {
+		t.Errorf("count should be skipped: %v", page)
+	}
+
+	page = list(fmt.Sprintf("&skip_count=true&page=%d", 2))
+	if _, ok := page["pages"]; ok || page["has_more"] != false {
+		t.Errorf("unexpected last page without count: %v", page)
+	}
+}
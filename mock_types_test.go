@@ -1,5 +1,7 @@
 package simpleapi
 
+import "time"
+
 type MockAppContext struct {
 }
 
@@ -9,3 +11,83 @@ type MockEvent struct {
 	SoftDeleted bool `api:"_deleted" simpleapi:"softdelete,adminonly"`
 	Label       string
 }
+
+type MockAccount struct {
+	Id uint64
+
+	Email    string `validate:"required,email"`
+	Nickname string `api:"nick" validate:"min=3,max=12,regex=^[a-z,]+$"`
+	Age      int    `validate:"min=18,max=130"`
+	Plan     string `validate:"oneof=free pro"`
+}
+
+type MockReport struct {
+	Id uint64
+
+	Title  string
+	Notes  string `role:"0,2"`
+	Salary uint64 `role:"2,2"`
+}
+
+type MockArticle struct {
+	Id uint64
+
+	Title    string
+	Featured bool   `role:"editor"`
+	Note     string `role:"moderator,moderator"`
+}
+
+type MockDocument struct {
+	Id uint64
+
+	Body    string
+	Version uint32 `simpleapi:"version"`
+}
+
+type MockNote struct {
+	Id uint64
+
+	Text      string
+	RemovedAt *time.Time `api:"removed_at" simpleapi:"softdelete"`
+	RemovedBy uint64     `simpleapi:"deletedby"`
+}
+
+type MockTicket struct {
+	Id uint64
+
+	Title    string
+	AuthorId uint64  `simpleapi:"createdby"`
+	EditorId *uint64 `simpleapi:"updatedby"`
+}
+
+type MockMemo struct {
+	Id uint64
+
+	OwnerId uint64 `simpleapi:"userid"`
+	Text    string
+	Removed bool `simpleapi:"softdelete"`
+}
+
+type MockPage struct {
+	Id uint64
+
+	Body    string
+	Version uint32 `simpleapi:"version"`
+	Removed bool   `simpleapi:"softdelete"`
+}
+
+type MockSecret struct {
+	Id uint64
+
+	Label string
+	Pin   string `out:"-"`
+}
+
+type MockProfile struct {
+	Id uint64
+
+	Nick     string `api:"handle"`
+	Bio      string `fill:"about"`
+	Password string `out:"-"`
+	Computed string `fill:"-"`
+}
@@ -0,0 +1,599 @@
+package simpleapi
+
+import (
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// describes a generated crud, see CrudGroup.Resources
+type ResourceInfo struct {
+	// model type name without package
+	Name string
+	// import path of model package
+	Package string
+	// collection path, as registered in router
+	Path string
+
+	PrimaryColumn string
+
+	Model FieldsMapping
+	// declared names of model fields in struct order
+	FieldOrder []string
+
+	Disabled  EndpointsDisableConfig
+	Templates []QueryTemplateInfo
+
+	// has-many filter names, see FieldFilter
+	RelationFilters []string
+	// fill names excluded from filtering
+	NotFilterable map[string]bool
+
+	SoftDelete   bool
+	Audit        bool
+	Revisions    bool
+	Idempotent   bool
+	CursorPaging bool
+	// bulk update and delete by filter are generated
+	BulkByFilter bool
+}
+
+type QueryTemplateInfo struct {
+	Name         string
+	RequiredArgs []string
+	Filter       HM
+}
+
+func (result *CrudConfig[T, CtxType]) resourceInfo() ResourceInfo {
+
+	typ := reflect.Indirect(reflect.ValueOf(result.Model)).Type()
+
+	info := ResourceInfo{
+		Name:          typ.Name(),
+		Package:       typ.PkgPath(),
+		PrimaryColumn: result.primaryIdDbName,
+		Model:         result.TypeDataModel,
+		Disabled:      result.disableEndpoints,
+		NotFilterable: result.disableFilterOverFields,
+
+		SoftDelete:   result.TypeDataModel.SoftDeleteField.Has,
+		Audit:        result.audit,
+		Revisions:    result.revisions,
+		Idempotent:   result.idempotencyWindow > 0,
+		CursorPaging: result.paging.Cursor,
+		BulkByFilter: len(result.existing) == 0,
+	}
+
+	if result.ParentGroup != nil {
+		info.Path = result.ParentGroup.BasePath()
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+
+		declName := typ.Field(i).Name
+
+		fieldInfo, ok := result.TypeDataModel.Fields[declName]
+		if !ok || fieldInfo.Internal || fieldInfo.DeclError {
+			continue
+		}
+
+		info.FieldOrder = append(info.FieldOrder, declName)
+	}
+
+	for name, it := range result.predefinedQueries {
+		info.Templates = append(info.Templates, QueryTemplateInfo{
+			Name:         name,
+			RequiredArgs: it.requiredArgs,
+			Filter:       it.filters,
+		})
+	}
+
+	sort.Slice(info.Templates, func(i, j int) bool {
+		return info.Templates[i].Name < info.Templates[j].Name
+	})
+
+	for _, it := range result.hasManyConfig {
+		info.RelationFilters = append(info.RelationFilters, it.FilterName)
+	}
+
+	return info
+}
+
+var routeParamRe = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// router `:param` path to openapi `{param}` one
+func openapiPath(path string) string {
+	return routeParamRe.ReplaceAllString(path, "{$1}")
+}
+
+// unique names of resources, used for schemas and operation ids.
+// bare model name if it is not taken by another resource, otherwise qualified by
+// package name and, for the same model mounted several times, by path as well
+func resourceNames(infos []ResourceInfo) []string {
+
+	qualify := func(names []string, next func(info ResourceInfo, name string) string) []string {
+
+		counts := map[string]int{}
+		for _, name := range names {
+			counts[name]++
+		}
+
+		result := []string{}
+		for idx, name := range names {
+			if counts[name] > 1 {
+				name = next(infos[idx], name)
+			}
+			result = append(result, name)
+		}
+
+		return result
+	}
+
+	names := []string{}
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+
+	names = qualify(names, func(info ResourceInfo, name string) string {
+		return tsPascal(path.Base(info.Package)) + name
+	})
+
+	return qualify(names, func(info ResourceInfo, name string) string {
+		return name + tsPascal(routeParamRe.ReplaceAllString(info.Path, ""))
+	})
+}
+
+func schemaRef(name string) HM {
+	return HM{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonResponse(description string, schema HM) HM {
+	return HM{
+		"description": description,
+		"content": HM{
+			"application/json": HM{"schema": schema},
+		},
+	}
+}
+
+func jsonBody(schema HM) HM {
+	return HM{
+		"required": true,
+		"content": HM{
+			"application/json": HM{"schema": schema},
+		},
+	}
+}
+
+// list parameters, taken from ListQueryParams form tags so they never drift
+func listParameters() []HM {
+
+	params := []HM{}
+
+	typ := reflect.TypeOf(ListQueryParams{})
+
+	for i := 0; i < typ.NumField(); i++ {
+
+		field := typ.Field(i)
+
+		name, ok := field.Tag.Lookup("form")
+		if !ok || name == "" || name == "-" {
+			continue
+		}
+
+		params = append(params, HM{
+			"name":   strings.Split(name, ",")[0],
+			"in":     "query",
+			"schema": typeSchema(field.Type),
+		})
+	}
+
+	return params
+}
+
+func pathParameters(path string) []HM {
+
+	params := []HM{}
+
+	for _, it := range routeParamRe.FindAllStringSubmatch(path, -1) {
+		params = append(params, HM{
+			"name":     it[1],
+			"in":       "path",
+			"required": true,
+			"schema":   HM{"type": "string"},
+		})
+	}
+
+	return params
+}
+
+// copy of base parameters with extra ones appended
+func withParams(base []HM, extra ...HM) []HM {
+
+	result := append([]HM{}, base...)
+
+	return append(result, extra...)
+}
+
+func operation(tag string, id string, params []HM) HM {
+
+	op := HM{
+		"tags":        []string{tag},
+		"operationId": id,
+		"responses":   HM{},
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	return op
+}
+
+// openapi path items of a single resource, name is taken from resourceNames
+func resourcePaths(info ResourceInfo, name string) HM {
+
+	paths := HM{}
+	itemPath := info.Path + "/:id"
+
+	collectionParams := pathParameters(info.Path)
+	itemParams := pathParameters(itemPath)
+
+	addOp := func(path string, method string, op HM) {
+		key := openapiPath(path)
+
+		item, ok := paths[key].(HM)
+		if !ok {
+			item = HM{}
+			paths[key] = item
+		}
+
+		item[method] = op
+	}
+
+	okResp := jsonResponse("ok", HM{"type": "object"})
+
+	if !info.Disabled.List {
+		op := operation(name, "list"+name, withParams(collectionParams, listParameters()...))
+		op["responses"] = HM{"200": jsonResponse("list of objects", HM{
+			"type": "object",
+			"properties": HM{
+				"items":       HM{"type": "array", "items": schemaRef(name)},
+				"has_more":    HM{"type": "boolean"},
+				"pages":       HM{"type": "integer"},
+				"total_items": HM{"type": "integer"},
+				"next_cursor": HM{"type": "string"},
+				"prev_cursor": HM{"type": "string"},
+			},
+		})}
+		addOp(info.Path, "get", op)
+	}
+
+	if !info.Disabled.Create {
+		op := operation(name, "create"+name, collectionParams)
+		op["requestBody"] = jsonBody(schemaRef(name + "Create"))
+		op["responses"] = HM{"200": jsonResponse("created object", HM{
+			"type": "object",
+			"properties": HM{
+				"created": HM{"type": "boolean"},
+				"object":  schemaRef(name),
+			},
+		})}
+		addOp(info.Path, "post", op)
+
+		if !info.Disabled.BulkCreate {
+			op := operation(name, "bulkCreate"+name, withParams(collectionParams, HM{
+				"name":   "partial",
+				"in":     "query",
+				"schema": HM{"type": "boolean"},
+			}))
+			op["requestBody"] = jsonBody(HM{"type": "array", "items": schemaRef(name + "Create")})
+			op["responses"] = HM{"200": okResp}
+			addOp(info.Path+"/bulk", "post", op)
+		}
+	}
+
+	if info.BulkByFilter && !info.Disabled.Update && !info.Disabled.BulkUpdate {
+		op := operation(name, "bulkUpdate"+name, collectionParams)
+		op["requestBody"] = jsonBody(HM{
+			"type": "object",
+			"properties": HM{
+				"filter":  HM{"type": "object"},
+				"patch":   schemaRef(name + "Update"),
+				"confirm": HM{"type": "boolean"},
+			},
+		})
+		op["responses"] = HM{"200": okResp}
+		addOp(info.Path, "patch", op)
+	}
+
+	if info.BulkByFilter && !info.Disabled.Delete && !info.Disabled.BulkDelete {
+		op := operation(name, "bulkDelete"+name, collectionParams)
+		op["requestBody"] = jsonBody(HM{
+			"type": "object",
+			"properties": HM{
+				"filter":  HM{"type": "object"},
+				"confirm": HM{"type": "boolean"},
+			},
+		})
+		op["responses"] = HM{"200": okResp}
+		addOp(info.Path, "delete", op)
+	}
+
+	if !info.Disabled.Get {
+		op := operation(name, "get"+name, withParams(itemParams, HM{
+			"name":   "fields",
+			"in":     "query",
+			"schema": HM{"type": "string"},
+		}))
+		op["responses"] = HM{"200": jsonResponse("object", HM{
+			"type":       "object",
+			"properties": HM{"item": schemaRef(name)},
+		})}
+		addOp(itemPath, "get", op)
+	}
+
+	if !info.Disabled.Update {
+		op := operation(name, "update"+name, itemParams)
+		op["requestBody"] = jsonBody(schemaRef(name + "Update"))
+		op["responses"] = HM{"200": jsonResponse("updated object", HM{
+			"type":       "object",
+			"properties": HM{"item": schemaRef(name)},
+		})}
+		addOp(itemPath, "patch", op)
+	}
+
+	if !info.Disabled.Delete {
+		params := itemParams
+
+		if info.SoftDelete {
+			params = withParams(params, HM{
+				"name":   "purge",
+				"in":     "query",
+				"schema": HM{"type": "boolean"},
+			})
+		}
+
+		op := operation(name, "delete"+name, params)
+		op["responses"] = HM{"200": okResp}
+		addOp(itemPath, "delete", op)
+
+		if info.SoftDelete {
+			op := operation(name, "restore"+name, itemParams)
+			op["responses"] = HM{"200": okResp}
+			addOp(itemPath+"/restore", "post", op)
+		}
+	}
+
+	if info.SoftDelete && !info.Disabled.List {
+		op := operation(name, "listTrash"+name, withParams(collectionParams, listParameters()...))
+		op["responses"] = HM{"200": okResp}
+		addOp(info.Path+"/trash", "get", op)
+	}
+
+	if info.Audit {
+		op := operation(name, "history"+name, itemParams)
+		op["responses"] = HM{"200": okResp}
+		addOp(itemPath+"/history", "get", op)
+	}
+
+	if info.Revisions {
+		revisionParams := withParams(itemParams, pathParameters("/:n")...)
+
+		op := operation(name, "listVersions"+name, itemParams)
+		op["responses"] = HM{"200": okResp}
+		addOp(itemPath+"/versions", "get", op)
+
+		op = operation(name, "getVersion"+name, revisionParams)
+		op["responses"] = HM{"200": okResp}
+		addOp(itemPath+"/versions/:n", "get", op)
+
+		if !info.Disabled.Update {
+			op = operation(name, "revertVersion"+name, revisionParams)
+			op["responses"] = HM{"200": okResp}
+			addOp(itemPath+"/versions/:n/revert", "post", op)
+		}
+	}
+
+	return paths
+}
+
+func supportedFilterOperators() []string {
+
+	ops := []string{}
+
+	for name := range supportedFilters {
+		ops = append(ops, name)
+	}
+
+	sort.Strings(ops)
+
+	return ops
+}
+
+// machine readable description of a resource, consumed by client generators.
+// served as `x-simpleapi-resources` of the OpenAPI document
+type ResourceDoc struct {
+	Name            string             `json:"name"`
+	Path            string             `json:"path"`
+	PrimaryKey      string             `json:"primary_key"`
+	Fields          []FieldDoc         `json:"fields"`
+	FilterOperators []string           `json:"filter_operators"`
+	RelationFilters []string           `json:"relation_filters"`
+	QueryTemplates  []QueryTemplateDoc `json:"query_templates"`
+	Disabled        map[string]bool    `json:"disabled"`
+	Features        map[string]bool    `json:"features"`
+}
+
+type FieldDoc struct {
+	Name       string `json:"name,omitempty"`
+	FillName   string `json:"fill_name,omitempty"`
+	Column     string `json:"column"`
+	Schema     HM     `json:"schema"`
+	Fillable   bool   `json:"fillable"`
+	Outable    bool   `json:"outable"`
+	Filterable bool   `json:"filterable"`
+	AdminOnly  bool   `json:"admin_only"`
+	ReadRole   string `json:"read_role,omitempty"`
+	WriteRole  string `json:"write_role,omitempty"`
+	Validate   string `json:"validate,omitempty"`
+}
+
+// template filter is not published, it is server side only
+type QueryTemplateDoc struct {
+	Name         string   `json:"name"`
+	RequiredArgs []string `json:"required_args"`
+}
+
+func resourceDoc(info ResourceInfo, name string) ResourceDoc {
+
+	doc := ResourceDoc{
+		Name:            name,
+		Path:            openapiPath(info.Path),
+		PrimaryKey:      info.PrimaryColumn,
+		Fields:          []FieldDoc{},
+		FilterOperators: supportedFilterOperators(),
+		RelationFilters: info.RelationFilters,
+		QueryTemplates:  []QueryTemplateDoc{},
+		Disabled: map[string]bool{
+			"list":        info.Disabled.List,
+			"create":      info.Disabled.Create,
+			"get":         info.Disabled.Get,
+			"update":      info.Disabled.Update,
+			"delete":      info.Disabled.Delete,
+			"bulk_create": info.Disabled.BulkCreate,
+			"bulk_update": info.Disabled.BulkUpdate || !info.BulkByFilter,
+			"bulk_delete": info.Disabled.BulkDelete || !info.BulkByFilter,
+		},
+		Features: map[string]bool{
+			"soft_delete":   info.SoftDelete,
+			"audit":         info.Audit,
+			"revisions":     info.Revisions,
+			"idempotent":    info.Idempotent,
+			"cursor_paging": info.CursorPaging,
+		},
+	}
+
+	for _, declName := range info.FieldOrder {
+
+		fieldInfo := info.Model.Fields[declName]
+
+		field := FieldDoc{
+			Column:    fieldInfo.TableColumnName,
+			Schema:    typeSchema(fieldInfo.NativeType),
+			Fillable:  fieldInfo.Fillable,
+			Outable:   fieldInfo.Outable,
+			AdminOnly: fieldInfo.AdminOnly,
+			ReadRole:  roleDeclString(fieldInfo.ReadRole, fieldInfo.ReadRoleName),
+			WriteRole: roleDeclString(fieldInfo.WriteRole, fieldInfo.WriteRoleName),
+		}
+
+		if fieldInfo.Outable {
+			field.Name = *fieldInfo.Name
+		}
+
+		// filters are looked up by fill name, same as the filter compiler does
+		if fieldInfo.Fillable {
+			field.FillName = *fieldInfo.FillName
+			field.Filterable = info.Model.Filterable[field.FillName] && !info.NotFilterable[field.FillName]
+		}
+
+		if fieldInfo.Validate != nil {
+			field.Validate = *fieldInfo.Validate
+		}
+
+		doc.Fields = append(doc.Fields, field)
+	}
+
+	for _, it := range info.Templates {
+		doc.QueryTemplates = append(doc.QueryTemplates, QueryTemplateDoc{
+			Name:         it.Name,
+			RequiredArgs: it.RequiredArgs,
+		})
+	}
+
+	return doc
+}
+
+// ResourceDocs describes every crud generated within the group, in registration order
+func (g *CrudGroup[T]) ResourceDocs() []ResourceDoc {
+
+	docs := []ResourceDoc{}
+
+	infos := g.Resources()
+	names := resourceNames(infos)
+
+	for idx, info := range infos {
+		docs = append(docs, resourceDoc(info, names[idx]))
+	}
+
+	return docs
+}
+
+func registeredRolesExtension() []HM {
+
+	roles := []HM{}
+
+	for _, role := range registeredRoleList() {
+		roles = append(roles, HM{
+			"name":     role.Name,
+			"group":    role.Group,
+			"inherits": role.Inherits,
+		})
+	}
+
+	return roles
+}
+
+// OpenAPI builds an OpenAPI 3.1 document of every crud generated within the group
+func (g *CrudGroup[T]) OpenAPI(title string, version string) HM {
+
+	paths := HM{}
+	schemas := HM{}
+	resources := []ResourceDoc{}
+
+	infos := g.Resources()
+	names := resourceNames(infos)
+
+	for idx, info := range infos {
+
+		name := names[idx]
+
+		for path, item := range resourcePaths(info, name) {
+			paths[path] = item
+		}
+
+		// the same schemas as served by ServeJsonSchemas
+		schemas[name] = modelSchema(info.Model, SchemaOutput)
+		schemas[name+"Create"] = modelSchema(info.Model, SchemaCreate)
+		schemas[name+"Update"] = modelSchema(info.Model, SchemaUpdate)
+
+		resources = append(resources, resourceDoc(info, name))
+	}
+
+	return HM{
+		"openapi": "3.1.0",
+		"info": HM{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": HM{
+			"schemas": schemas,
+		},
+		"x-simpleapi-resources": resources,
+		"x-simpleapi-roles":     registeredRolesExtension(),
+	}
+}
+
+// ServeOpenAPI registers `/openapi.json` endpoint, document is built on each request,
+// so cruds generated after the call are included too
+func (g *CrudGroup[T]) ServeOpenAPI(router Router, title string, version string) {
+
+	router.Handle(http.MethodGet, "/openapi.json", func(ctx Request) {
+		ctx.JSON(200, g.OpenAPI(title, version))
+	})
+}
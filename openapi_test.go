@@ -0,0 +1,170 @@
+package simpleapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func mockCrudGroup(router Router) *CrudGroup[MockAppContext] {
+
+	app := NewAppContext(&MockAppContext{})
+
+	app.SetObjectsMapping(map[string]FieldsMapping{
+		GetObjectType(MockEvent{}):   GetFieldTags[MockAppContext](MockEvent{}),
+		GetObjectType(MockAccount{}): GetFieldTags[MockAppContext](MockAccount{}),
+		GetObjectType(MockProfile{}): GetFieldTags[MockAppContext](MockProfile{}),
+	})
+
+	crudGroup := NewCrudGroup(*app, CrudGroupConfig[MockAppContext]{
+		ObjectIdFieldName: "id",
+	})
+
+	New(crudGroup, router.Group("/events"), MockEvent{}).Generate()
+	New(crudGroup, router.Group("/accounts"), MockAccount{}).Disable(EndpointsDisableConfig{Delete: true}).Generate()
+	New(crudGroup, router.Group("/profiles"), MockProfile{}).Generate()
+
+	return crudGroup
+}
+
+func TestOpenAPI(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+
+	crudGroup := mockCrudGroup(GinRouter(&router.RouterGroup))
+	crudGroup.ServeOpenAPI(GinRouter(&router.RouterGroup), "mock", "1")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	if w.Code != 200 {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+
+	doc := map[string]any{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("bad document: %s", err.Error())
+	}
+
+	paths := doc["paths"].(map[string]any)
+
+	eventItem, ok := paths["/events/{id}"].(map[string]any)
+	if !ok || eventItem["delete"] == nil || eventItem["patch"] == nil {
+		t.Errorf("event item operations are missing: %v", paths["/events/{id}"])
+	}
+
+	if paths["/events/trash"] == nil {
+		t.Errorf("soft removable resource should have a trash endpoint")
+	}
+
+	accountItem := paths["/accounts/{id}"].(map[string]any)
+	if accountItem["delete"] != nil {
+		t.Errorf("disabled endpoint is documented")
+	}
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+
+	deleted := schemas["MockEvent"].(map[string]any)["properties"].(map[string]any)["_deleted"].(map[string]any)
+	if deleted["x-simpleapi-admin-only"] != true {
+		t.Errorf("admin only field is not annotated: %v", deleted)
+	}
+
+	required := schemas["MockAccountCreate"].(map[string]any)["required"].([]any)
+	if len(required) != 1 || required[0] != "email" {
+		t.Errorf("required fields are not taken from validate rules: %v", required)
+	}
+
+	resources := doc["x-simpleapi-resources"].([]any)
+	if len(resources) != 3 || resources[0].(map[string]any)["name"] != "MockEvent" {
+		t.Fatalf("resources are not described in registration order: %v", resources)
+	}
+
+	profileFields := map[string]map[string]any{}
+	for _, it := range resources[2].(map[string]any)["fields"].([]any) {
+		field := it.(map[string]any)
+		profileFields[field["column"].(string)] = field
+	}
+
+	if _, ok := profileFields["password"]["name"]; ok || profileFields["password"]["fill_name"] != "password" {
+		t.Errorf("not outable field should have fill name only: %v", profileFields["password"])
+	}
+
+	if _, ok := profileFields["computed"]["fill_name"]; ok || profileFields["computed"]["name"] != "computed" {
+		t.Errorf("not fillable field should have out name only: %v", profileFields["computed"])
+	}
+
+	if profileFields["nick"]["fill_name"] != "handle" || profileFields["bio"]["fill_name"] != "about" {
+		t.Errorf("renamed fields should be documented by their fill names: %v", profileFields)
+	}
+
+	// filterable flag should match what the filter compiler accepts from a regular user
+	profileCrud := &CrudConfig[MockProfile, MockAppContext]{
+		TypeDataModel:   GetFieldTags[MockAppContext](MockProfile{}),
+		tableName:       "mock_profiles",
+		primaryIdDbName: "id",
+		objectIdField:   "id",
+	}
+
+	for column, field := range profileFields {
+
+		fillName, fillable := field["fill_name"].(string)
+		if !fillable {
+			if field["filterable"] != false {
+				t.Errorf("not fillable field `%s` can't be filtered", column)
+			}
+			continue
+		}
+
+		compiled := prepareFilterData[MockProfile, MockAppContext](HM{fillName: "x"}, profileCrud, profileCrud.TypeDataModel, RequestData{}, ListQueryParams{}).Unwrap()
+
+		if field["filterable"] != (compiled.QueryPlaceholder != "") {
+			t.Errorf("filterable flag of `%s` differs from compiled filter `%s`: %v", fillName, compiled.QueryPlaceholder, field)
+		}
+	}
+}
+
+func TestOpenAPIResourceNames(t *testing.T) {
+
+	names := resourceNames([]ResourceInfo{
+		{Name: "Invoice", Package: "example.com/billing", Path: "/billing/invoices"},
+		{Name: "Invoice", Package: "example.com/crm", Path: "/crm/invoices"},
+		{Name: "Event", Package: "example.com/crm", Path: "/events"},
+		{Name: "Event", Package: "example.com/crm", Path: "/users/:user/events"},
+		{Name: "Account", Package: "example.com/crm", Path: "/accounts"},
+	})
+
+	expected := []string{"BillingInvoice", "CrmInvoice", "CrmEventEvents", "CrmEventUsersEvents", "Account"}
+
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected resource names: %v", names)
+	}
+}
+
+func TestOpenAPITemplateFilters(t *testing.T) {
+
+	app := NewAppContext(&MockAppContext{})
+
+	app.SetObjectsMapping(map[string]FieldsMapping{
+		GetObjectType(MockEvent{}): GetFieldTags[MockAppContext](MockEvent{}),
+	})
+
+	crudGroup := NewCrudGroup(*app, CrudGroupConfig[MockAppContext]{
+		ObjectIdFieldName: "id",
+	})
+
+	New(crudGroup, ServeMuxRouter(http.NewServeMux()).Group("/events"), MockEvent{}).
+		AddQueryTemplate("internal", ListQueryParams{}, HM{"label": "secret"}, nil).
+		Generate()
+
+	encoded, _ := json.Marshal(crudGroup.OpenAPI("mock", "1")["x-simpleapi-resources"])
+
+	if strings.Contains(string(encoded), "secret") || !strings.Contains(string(encoded), "internal") {
+		t.Errorf("template filter should not be published: %s", encoded)
+	}
+}
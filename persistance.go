@@ -1,7 +1,9 @@
 package simpleapi
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/dot5enko/typed"
 	"gorm.io/gorm"
@@ -24,6 +26,20 @@ type OnBeforeUpdateCbAware[CtxType any] interface {
 	BeforeUpdate(ctx *AppContext[CtxType]) error
 }
 
+type BeforeDeleteCbAware[CtxType any] interface {
+	BeforeEntityDelete(ctx *AppContext[CtxType]) error
+}
+
+type AfterDeleteCbAware[CtxType any] interface {
+	AfterEntityDelete(ctx *AppContext[CtxType]) error
+}
+
+// invoked once the transaction that stored the object is committed.
+// ctx is not bound to the transaction anymore
+type AfterCommitCbAware[CtxType any] interface {
+	AfterEntityCommit(ctx *AppContext[CtxType], op EntityOperation)
+}
+
 type OnUpdateEventHandler[CtxType any, T any] interface {
 	OnUpdate(ctx *AppContext[CtxType], prevState T, permission RequestData) error
 }
@@ -76,140 +92,288 @@ func (d *DbWrapper[CtxType]) setRaw(_db *gorm.DB) {
 	d.db = _db
 }
 
-func _isolatedCreate[CtxType any](obj any, ctx AppContext[CtxType]) (err error) {
+type EntityOperation string
 
-	_db := ctx.Db.Raw()
+const (
+	OperationCreate EntityOperation = "create"
+	OperationUpdate EntityOperation = "update"
+	OperationDelete EntityOperation = "delete"
+)
+
+var ErrHookPanic = errors.New("hook panicked")
+
+// runs userland hook, panics are turned into errors so the transaction is rolled back
+func callHook(name string, hook func() error) (err error) {
 
-	__obj, ok := obj.(OnBeforeUpdateCbAware[CtxType])
-	if ok {
-		beforeUpdateCbErr := __obj.BeforeUpdate(&ctx)
-		if beforeUpdateCbErr != nil {
-			return beforeUpdateCbErr
+	defer func() {
+		rec := recover()
+		if rec != nil {
+			err = fmt.Errorf("%w: %s: %v", ErrHookPanic, name, rec)
+		}
+	}()
+
+	return hook()
+}
+
+func beforeEntityHook[CtxType any](obj any, ctx *AppContext[CtxType], op EntityOperation) error {
+
+	switch op {
+	case OperationCreate:
+		if h, ok := obj.(BeforeCreateCbAware[CtxType]); ok {
+			return callHook("BeforeEntityCreate", func() error { return h.BeforeEntityCreate(ctx) })
+		}
+	case OperationUpdate:
+		if h, ok := obj.(OnBeforeUpdateCbAware[CtxType]); ok {
+			return callHook("BeforeUpdate", func() error { return h.BeforeUpdate(ctx) })
+		}
+	case OperationDelete:
+		if h, ok := obj.(BeforeDeleteCbAware[CtxType]); ok {
+			return callHook("BeforeEntityDelete", func() error { return h.BeforeEntityDelete(ctx) })
 		}
 	}
 
-	err = _db.Create(obj).Error
+	return nil
+}
+
+func afterEntityHook[CtxType any](obj any, ctx *AppContext[CtxType], op EntityOperation) (err error) {
+
+	switch op {
+	case OperationCreate:
+		if h, ok := obj.(AfterCreateCbAware[CtxType]); ok {
+			err = callHook("AfterEntityCreate", func() error { return h.AfterEntityCreate(ctx) })
+		}
+	case OperationUpdate:
+		if h, ok := obj.(OnAfterUpdateCbAware[CtxType]); ok {
+			err = callHook("AfterUpdate", func() error { return h.AfterUpdate(ctx) })
+		}
+	case OperationDelete:
+		if h, ok := obj.(AfterDeleteCbAware[CtxType]); ok {
+			err = callHook("AfterEntityDelete", func() error { return h.AfterEntityDelete(ctx) })
+		}
+	}
 
 	if err != nil {
 		return err
 	}
 
-	// check after event
-	_obj, ok := obj.(AfterCreateCbAware[CtxType])
-	if ok {
-		return _obj.AfterEntityCreate(&ctx)
+	if h, ok := obj.(AfterCommitCbAware[CtxType]); ok {
+
+		committed := ctx.detached()
+
+		// panics are recovered by DbTransaction
+		ctx.OnCommit(func() {
+			h.AfterEntityCommit(committed, op)
+		})
 	}
 
 	return nil
 }
 
-func _isolatedSaveOnlyFields[CtxType any](obj any, ctx AppContext[CtxType], fields []string) (err error) {
+// runs processor in a transaction, unless wrapper is already bound to one.
+// after commit callbacks are invoked by the top level DbTransaction
+func (d DbWrapper[CtxType]) transaction(processor TransactionProcessor[CtxType]) error {
 
-	_db := ctx.Db.Raw()
-
-	__obj, ok := obj.(OnBeforeUpdateCbAware[CtxType])
-	if ok {
-		beforeUpdateCbErr := __obj.BeforeUpdate(&ctx)
-		if beforeUpdateCbErr != nil {
-			return beforeUpdateCbErr
-		}
+	if d.app.isolated {
+		return processor(*d.app)
 	}
 
-	if len(fields) > 0 {
-		err = _db.Select(fields).Updates(obj).Error
+	return d.app.DbTransaction(processor)
+}
 
-	} else {
-		err = _db.Save(obj).Error
-	}
+// stores object with lifecycle hooks of op invoked around the store call
+func _isolatedWithHooks[CtxType any](obj any, ctx AppContext[CtxType], op EntityOperation, store func(_db *gorm.DB) error) (err error) {
 
+	err = beforeEntityHook(obj, &ctx, op)
 	if err != nil {
 		return err
 	}
 
-	// check after event
-	// should be executed after transaction commit
-	_obj, ok := obj.(OnAfterUpdateCbAware[CtxType])
-	if ok {
-		return _obj.AfterUpdate(&ctx)
+	err = store(ctx.Db.Raw())
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return afterEntityHook(obj, &ctx, op)
 }
 
-func _isolatedSave[CtxType any](obj any, ctx AppContext[CtxType]) (err error) {
+// creates a slice of objects, hooks are invoked for each of them
+func _isolatedCreateBatch[CtxType any](objs any, ctx AppContext[CtxType], batchSize int) (err error) {
 
-	_db := ctx.Db.Raw()
+	elems := reflect.Indirect(reflect.ValueOf(objs))
 
-	__obj, ok := obj.(OnBeforeUpdateCbAware[CtxType])
-	if ok {
-		beforeUpdateCbErr := __obj.BeforeUpdate(&ctx)
-		if beforeUpdateCbErr != nil {
-			return beforeUpdateCbErr
+	for i := 0; i < elems.Len(); i++ {
+		err = beforeEntityHook(elems.Index(i).Addr().Interface(), &ctx, OperationCreate)
+		if err != nil {
+			return err
 		}
 	}
 
-	err = _db.Save(obj).Error
-
+	err = ctx.Db.Raw().CreateInBatches(objs, batchSize).Error
 	if err != nil {
 		return err
 	}
 
-	// check after event
-	// should be executed after transaction commit
-	_obj, ok := obj.(OnAfterUpdateCbAware[CtxType])
-	if ok {
-		return _obj.AfterUpdate(&ctx)
+	for i := 0; i < elems.Len(); i++ {
+		err = afterEntityHook(elems.Index(i).Addr().Interface(), &ctx, OperationCreate)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (d DbWrapper[CtxType]) UpdateFields(obj any, fields ...string) (err error) {
+// saves object only if row still matches `where` condition, eg has the same version.
+// returns ErrVersionConflict if no row was updated
+func _saveWhere(_db *gorm.DB, obj any, where string, whereArgs ...any) error {
 
-	if d.app.isolated {
-		return _isolatedSaveOnlyFields(obj, *d.app, fields)
+	updated := _db.Model(obj).Where(where, whereArgs...).Select("*").Updates(obj)
+
+	if updated.Error != nil {
+		return updated.Error
 	}
 
-	return d.db.Transaction(func(tx *gorm.DB) error {
-		return _isolatedSaveOnlyFields(obj, d.app.isolateDatabase(tx), fields)
+	if updated.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	return nil
+}
+
+// saves object guarded by a version column, version value should be already incremented
+func (d DbWrapper[CtxType]) SaveVersioned(obj any, versionColumn string, prevVersion any) (err error) {
+
+	where := fmt.Sprintf("%s = ?", versionColumn)
+
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedWithHooks(obj, ctx, OperationUpdate, func(_db *gorm.DB) error {
+			return _saveWhere(_db, obj, where, prevVersion)
+		})
+	})
+}
+
+func (d DbWrapper[CtxType]) UpdateFields(obj any, fields ...string) (err error) {
+
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedWithHooks(obj, ctx, OperationUpdate, func(_db *gorm.DB) error {
+
+			// unscoped, so soft removed with gorm.DeletedAt objects could be restored
+			_db = _db.Unscoped()
+
+			if len(fields) > 0 {
+				return _db.Select(fields).Updates(obj).Error
+			}
+
+			return _db.Save(obj).Error
+		})
 	})
 }
 
 func (d DbWrapper[CtxType]) Save(obj any) (err error) {
 
-	if d.app.isolated {
-		return _isolatedSave(obj, *d.app)
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedWithHooks(obj, ctx, OperationUpdate, func(_db *gorm.DB) error {
+			return _db.Save(obj).Error
+		})
+	})
+}
+
+func (d DbWrapper[CtxType]) Create(obj any) (err error) {
+
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedWithHooks(obj, ctx, OperationCreate, func(_db *gorm.DB) error {
+			return _db.Create(obj).Error
+		})
+	})
+}
+
+// creates objects from a pointer to slice using multi row inserts
+func (d DbWrapper[CtxType]) CreateInBatches(objs any, batchSize int) (err error) {
+
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedCreateBatch(objs, ctx, batchSize)
+	})
+}
+
+func (d DbWrapper[CtxType]) Delete(obj any) (err error) {
+
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedWithHooks(obj, ctx, OperationDelete, func(_db *gorm.DB) error {
+			return _db.Delete(obj).Error
+		})
+	})
+}
+
+// SoftDelete stores soft removal fields, delete hooks are invoked as for a regular removal
+func (d DbWrapper[CtxType]) SoftDelete(obj any, fields ...string) (err error) {
+
+	if len(fields) == 0 {
+		return fmt.Errorf("no soft removal fields provided")
 	}
 
-	return d.db.Transaction(func(tx *gorm.DB) error {
-		return _isolatedSave(obj, d.app.isolateDatabase(tx))
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedWithHooks(obj, ctx, OperationDelete, func(_db *gorm.DB) error {
+			// unscoped, so gorm.DeletedAt soft removal does not interfere
+			return _db.Unscoped().Select(fields).Updates(obj).Error
+		})
 	})
 }
 
-func (d DbWrapper[CtxType]) Create(obj any) (err error) {
+// result of a statement guarded by version condition, no affected rows means a conflict
+func _affectedOrConflict(res *gorm.DB) error {
 
-	if d.app.isolated {
-		return _isolatedCreate(obj, *d.app)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrVersionConflict
 	}
 
-	return d.db.Transaction(func(tx *gorm.DB) error {
-		return _isolatedCreate(obj, d.app.isolateDatabase(tx))
+	return nil
+}
+
+// DeleteVersioned removes object only if its row still has the version, returns ErrVersionConflict otherwise.
+// unscoped removal bypasses gorm soft delete scope, as Purge does
+func (d DbWrapper[CtxType]) DeleteVersioned(obj any, versionColumn string, version any, unscoped bool) (err error) {
+
+	where := fmt.Sprintf("%s = ?", versionColumn)
+
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedWithHooks(obj, ctx, OperationDelete, func(_db *gorm.DB) error {
+			if unscoped {
+				_db = _db.Unscoped()
+			}
+			return _affectedOrConflict(_db.Where(where, version).Delete(obj))
+		})
 	})
 }
 
-func (d DbWrapper[CtxType]) Delete(obj any) (err error) {
+// SoftDeleteVersioned stores soft removal fields only if the row still has prevVersion,
+// version value should be already incremented and listed in fields
+func (d DbWrapper[CtxType]) SoftDeleteVersioned(obj any, versionColumn string, prevVersion any, fields ...string) (err error) {
 
-	err = d.Raw().Delete(obj).Error
+	if len(fields) == 0 {
+		return fmt.Errorf("no soft removal fields provided")
+	}
 
-	return
+	where := fmt.Sprintf("%s = ?", versionColumn)
 
-	// if d.app.isolated {
-	// 	return _isolatedCreate(obj, *d.app)
-	// }
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedWithHooks(obj, ctx, OperationDelete, func(_db *gorm.DB) error {
+			return _affectedOrConflict(_db.Unscoped().Model(obj).Where(where, prevVersion).Select(fields).Updates(obj))
+		})
+	})
+}
 
-	// return d.db.Transaction(func(tx *gorm.DB) error {
-	// 	return _isolatedCreate(obj, d.app.isolateDatabase(tx))
-	// })
+// Purge removes object permanently, bypassing gorm soft delete scope
+func (d DbWrapper[CtxType]) Purge(obj any) (err error) {
+
+	return d.transaction(func(ctx AppContext[CtxType]) error {
+		return _isolatedWithHooks(obj, ctx, OperationDelete, func(_db *gorm.DB) error {
+			return _db.Unscoped().Delete(obj).Error
+		})
+	})
 }
 
 func SortAndFindAllWhere[T any, CtxType any](db DbWrapper[CtxType], sortByField string, sortBy int, limit, offset int, where string, whereArgs ...any) typed.Result[[]T] {
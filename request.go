@@ -1,9 +1,7 @@
 package simpleapi
 
-import "github.com/gin-gonic/gin"
-
 type RequestContext[T any, X any] struct {
-	Http *gin.Context
+	Http Request
 	App  *AppContext[X]
 	Data *T
 }
@@ -24,16 +22,16 @@ func (handlers *HandlersChain[T, X]) Use(h ...RequestHandler[T, X]) {
 	*handlers = append(*handlers, h...)
 }
 
-func (handlers HandlersChain[T, X]) ProcessRequest(ginCtx *gin.Context, app *AppContext[X], requestCtx *T) {
+func (handlers HandlersChain[T, X]) ProcessRequest(req Request, app *AppContext[X], requestCtx *T) {
 
 	ourCtx := &RequestContext[T, X]{
-		Http: ginCtx,
+		Http: req,
 		App:  app,
 		Data: requestCtx,
 	}
 
 	for _, it := range handlers {
-		if !ginCtx.IsAborted() {
+		if !req.IsAborted() {
 			it(ourCtx)
 		} else {
 			break
@@ -0,0 +1,428 @@
+package simpleapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"gorm.io/gorm/clause"
+)
+
+// full state of an entity after a change, stored in `<table>_versions`
+type EntityRevision struct {
+	Id uint64
+
+	// unique together, index is created by Revisions as its name should differ per table
+	EntityId string `gorm:"size:64"`
+	Revision int
+
+	ActorId string      `gorm:"size:64"`
+	Action  AuditAction `gorm:"size:16"`
+
+	// json object of all outable fields: out name -> value
+	Snapshot string
+	// json object of all fillable fields: fill name -> value, used to revert
+	FillValues string
+
+	CreatedAt time.Time
+}
+
+// Revisions keeps a snapshot of an entity after every save, so earlier states could be read and reverted.
+// fillable fields are stored separately from the readable snapshot, so fields hidden with `out:"-"`
+// are restored by revert too. revisions stored without them are reverted only if
+// every fillable field is in the snapshot, 409 listing missing fields otherwise
+func (it *CrudConfig[T, CtxType]) Revisions() *CrudConfig[T, CtxType] {
+
+	if it.App.Db.automigrate {
+		err := it.migrateRevisions()
+		if err != nil {
+			panic(fmt.Sprintf("unable to migrate revisions table: %s", err.Error()))
+		}
+	}
+
+	it.revisions = true
+
+	return it
+}
+
+func (result *CrudConfig[T, CtxType]) revisionsTable() string {
+	return result.tableName + "_versions"
+}
+
+func (result *CrudConfig[T, CtxType]) migrateRevisions() error {
+
+	table := result.revisionsTable()
+	db := result.App.Db.Raw()
+
+	err := db.Table(table).AutoMigrate(&EntityRevision{})
+	if err != nil {
+		return err
+	}
+
+	// index names are global in some databases
+	index := fmt.Sprintf("idx_%s_entity_revision", table)
+
+	if db.Table(table).Migrator().HasIndex(&EntityRevision{}, index) {
+		return nil
+	}
+
+	return db.Exec(
+		"CREATE UNIQUE INDEX ? ON ? (?, ?)",
+		clause.Table{Name: index},
+		clause.Table{Name: table},
+		clause.Column{Name: "entity_id"},
+		clause.Column{Name: "revision"},
+	).Error
+}
+
+// writes all enabled change records: audit log and revision snapshot
+func (result *CrudConfig[T, CtxType]) recordChange(appctx *AppContext[CtxType], action AuditAction, prev *T, cur *T, reqData RequestData) error {
+
+	err := result.writeAudit(appctx, action, prev, cur, reqData)
+	if err != nil {
+		return err
+	}
+
+	// removed objects have no state to snapshot
+	if cur == nil {
+		return nil
+	}
+
+	return result.writeRevision(appctx, action, cur, reqData)
+}
+
+func (result *CrudConfig[T, CtxType]) writeRevision(appctx *AppContext[CtxType], action AuditAction, obj *T, reqData RequestData) error {
+
+	if !result.revisions {
+		return nil
+	}
+
+	snapshot, err := json.Marshal(result.auditSnapshot(obj))
+	if err != nil {
+		return fmt.Errorf("unable to encode revision snapshot: %s", err.Error())
+	}
+
+	fillValues, err := json.Marshal(result.fillSnapshot(obj))
+	if err != nil {
+		return fmt.Errorf("unable to encode revision values: %s", err.Error())
+	}
+
+	entityId := fmt.Sprintf("%v", result.primaryKeyValue(obj))
+
+	lastRevision := 0
+
+	err = appctx.Db.Raw().
+		Table(result.revisionsTable()).
+		Where("entity_id = ?", entityId).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&lastRevision).Error
+
+	if err != nil {
+		return err
+	}
+
+	actor := ""
+	if reqData.AuthorizedUserId != nil {
+		actor = fmt.Sprintf("%v", reqData.AuthorizedUserId)
+	}
+
+	revision := EntityRevision{
+		EntityId:   entityId,
+		Revision:   lastRevision + 1,
+		ActorId:    actor,
+		Action:     action,
+		Snapshot:   string(snapshot),
+		FillValues: string(fillValues),
+	}
+
+	reqData.log_format(" revision %d of %s #%s", revision.Revision, result.TypeDataModel.TypeName, entityId)
+
+	// concurrent saves of the same object fail on unique revision number
+	return appctx.Db.Raw().Table(result.revisionsTable()).Create(&revision).Error
+}
+
+func (result *CrudConfig[T, CtxType]) findRevision(appctx *AppContext[CtxType], obj T, n int) (revision EntityRevision, err error) {
+
+	err = appctx.Db.Raw().
+		Table(result.revisionsTable()).
+		Where("entity_id = ? AND revision = ?", fmt.Sprintf("%v", result.primaryKeyValue(&obj)), n).
+		First(&revision).Error
+
+	return
+}
+
+// declared names of fields restored by revert
+func (result *CrudConfig[T, CtxType]) revertableFields() []string {
+
+	fields := []string{}
+
+	for _, declName := range result.TypeDataModel.Fillable {
+		if result.TypeDataModel.Fields[declName].TableColumnName != result.primaryIdDbName {
+			fields = append(fields, declName)
+		}
+	}
+
+	return fields
+}
+
+// values of fields restored by revert: fill name -> value
+func (result *CrudConfig[T, CtxType]) fillSnapshot(obj *T) map[string]any {
+
+	snapshot := map[string]any{}
+
+	reflected := reflect.ValueOf(obj).Elem()
+
+	for _, declName := range result.revertableFields() {
+
+		fieldInfo := result.TypeDataModel.Fields[declName]
+
+		snapshot[*fieldInfo.FillName] = dtoValue(fieldInfo, reflected.FieldByName(declName).Interface())
+	}
+
+	return snapshot
+}
+
+// snapshot with fields not readable by request removed
+func (result *CrudConfig[T, CtxType]) readableSnapshot(revision EntityRevision, reqData RequestData) HM {
+
+	snapshot := HM{}
+	json.Unmarshal([]byte(revision.Snapshot), &snapshot)
+
+	for _, declName := range result.TypeDataModel.Outable {
+
+		fieldInfo := result.TypeDataModel.Fields[declName]
+
+		if !fieldInfo.Readable(reqData) {
+			delete(snapshot, *fieldInfo.Name)
+		}
+	}
+
+	return snapshot
+}
+
+func revisionNotFoundResp(n int) *RespErr {
+	return NewRespErr(404, HM{
+		"msg":      "revision not found",
+		"revision": n,
+	})
+}
+
+// ListRevisions lists revisions of an object without snapshots, newest first
+func (result *CrudConfig[T, CtxType]) ListRevisions(appctx *AppContext[CtxType], obj T, page int, reqData RequestData) *RespErr {
+
+	if page <= 0 {
+		page = 1
+	}
+
+	perPage := result.paging.PerPage
+
+	revisions := []EntityRevision{}
+
+	findErr := appctx.Db.Raw().
+		Table(result.revisionsTable()).
+		Omit("snapshot", "fill_values").
+		Where("entity_id = ?", fmt.Sprintf("%v", result.primaryKeyValue(&obj))).
+		Order("revision DESC").
+		Limit(perPage + 1).
+		Offset((page - 1) * perPage).
+		Find(&revisions).Error
+
+	if findErr != nil {
+
+		responseData := HM{
+			"msg": "unable to load revisions",
+		}
+
+		if reqData.IsAdmin {
+			responseData["err"] = findErr.Error()
+		}
+
+		return NewRespErr(500, responseData)
+	}
+
+	hasMore := len(revisions) > perPage
+	if hasMore {
+		revisions = revisions[:perPage]
+	}
+
+	items := []HM{}
+
+	for _, it := range revisions {
+		items = append(items, HM{
+			"revision":   it.Revision,
+			"action":     it.Action,
+			"actor_id":   it.ActorId,
+			"created_at": it.CreatedAt.Unix(),
+		})
+	}
+
+	return NewRespErr(200, HM{
+		"items":    items,
+		"has_more": hasMore,
+	})
+}
+
+// GetRevision returns state of an object at revision n
+func (result *CrudConfig[T, CtxType]) GetRevision(appctx *AppContext[CtxType], obj T, n int, reqData RequestData) *RespErr {
+
+	revision, err := result.findRevision(appctx, obj, n)
+	if err != nil {
+		return revisionNotFoundResp(n)
+	}
+
+	return NewRespErr(200, HM{
+		"revision":   revision.Revision,
+		"action":     revision.Action,
+		"actor_id":   revision.ActorId,
+		"created_at": revision.CreatedAt.Unix(),
+		"item":       result.readableSnapshot(revision, reqData),
+	})
+}
+
+// RevertToRevision applies state of revision n as a regular update,
+// so field permissions, validation and hooks are applied
+func (result *CrudConfig[T, CtxType]) RevertToRevision(appctx *AppContext[CtxType], obj T, n int, reqData RequestData) (objectReverted T, respData *RespErr) {
+
+	revision, err := result.findRevision(appctx, obj, n)
+	if err != nil {
+		respData = revisionNotFoundResp(n)
+		return
+	}
+
+	patch := HM{}
+
+	if revision.FillValues != "" {
+		json.Unmarshal([]byte(revision.FillValues), &patch)
+	} else {
+
+		snapshot := HM{}
+		json.Unmarshal([]byte(revision.Snapshot), &snapshot)
+
+		// snapshot keeps out names, patch is built from fill names
+		missing := []string{}
+
+		for _, declName := range result.revertableFields() {
+
+			fieldInfo := result.TypeDataModel.Fields[declName]
+
+			var value any
+			ok := false
+
+			if fieldInfo.Name != nil && fieldInfo.Outable {
+				value, ok = snapshot[*fieldInfo.Name]
+			}
+
+			if !ok {
+				missing = append(missing, *fieldInfo.FillName)
+				continue
+			}
+
+			patch[*fieldInfo.FillName] = value
+		}
+
+		if len(missing) > 0 {
+			respData = NewRespErr(409, HM{
+				"msg":    "revision can't be fully restored",
+				"fields": missing,
+			})
+			return
+		}
+	}
+
+	patchJson, _ := json.Marshal(patch)
+
+	reqData.log_format(" reverting to revision %d", n)
+
+	return result.UpdateEntity(appctx, obj, gjson.ParseBytes(patchJson), reqData)
+}
+
+func revisionParam(ctx Request) (int, bool) {
+
+	n, err := strconv.Atoi(ctx.Param("n"))
+	if err != nil || n <= 0 {
+		ctx.JSON(400, HM{
+			"msg": "bad revision number",
+		})
+		return 0, false
+	}
+
+	return n, true
+}
+
+func (result *CrudConfig[T, CtxType]) generateRevisionEndpoints(existingItems Router, writePermissionMiddleware HandlerFunc) {
+
+	if !result.revisions {
+		return
+	}
+
+	existingItems.Handle(http.MethodGet, "/versions", func(ctx Request) {
+
+		reqData := result.RequestData(ctx)
+
+		page, _ := strconv.Atoi(ctx.Query("page"))
+
+		listResp := result.ListRevisions(result.App, MustGetObjectFromContext[T](ctx, "_eobj"), page, reqData)
+
+		if reqData.Debug {
+			listResp.Data["logs"] = reqData.getDebugLogs()
+		}
+
+		ctx.JSON(listResp.Httpcode, listResp.Data)
+	})
+
+	existingItems.Handle(http.MethodGet, "/versions/:n", func(ctx Request) {
+
+		n, ok := revisionParam(ctx)
+		if !ok {
+			return
+		}
+
+		reqData := result.RequestData(ctx)
+
+		revisionResp := result.GetRevision(result.App, MustGetObjectFromContext[T](ctx, "_eobj"), n, reqData)
+
+		if reqData.Debug {
+			revisionResp.Data["logs"] = reqData.getDebugLogs()
+		}
+
+		ctx.JSON(revisionResp.Httpcode, revisionResp.Data)
+	})
+
+	if result.disableEndpoints.Update {
+		return
+	}
+
+	existingItems.Handle(http.MethodPost, "/versions/:n/revert", writePermissionMiddleware, func(ctx Request) {
+
+		n, ok := revisionParam(ctx)
+		if !ok {
+			return
+		}
+
+		reqData := result.RequestData(ctx)
+
+		modelCopy := MustGetObjectFromContext[T](ctx, "_eobj")
+
+		if !etagMatches(ctx.GetHeader("If-Match"), result.ETag(modelCopy)) {
+			conflict := versionConflictResp()
+			ctx.JSON(conflict.Httpcode, conflict.Data)
+			return
+		}
+
+		reverted, revertResp := result.RevertToRevision(result.App, modelCopy, n, reqData)
+
+		if revertResp.Httpcode == 200 {
+			ctx.Header("ETag", result.ETag(reverted))
+		}
+
+		if reqData.Debug {
+			revertResp.Data["logs"] = reqData.getDebugLogs()
+		}
+
+		ctx.JSON(revertResp.Httpcode, revertResp.Data)
+	})
+}
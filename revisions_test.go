@@ -0,0 +1,126 @@
+package simpleapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRevisions(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockAccount{}, &MockNote{})
+
+	router := ServeMuxRouter(mux)
+
+	// second model with revisions should migrate its own table and index
+	New(group, router.Group("/accounts"), MockAccount{}).Revisions().Generate()
+	New(group, router.Group("/notes"), MockNote{}).Revisions().Generate()
+
+	resp := mockRequest(t, mux, http.MethodPost, "/accounts", `{"email": "a@test.com", "nick": "alpha", "age": 20}`)
+	if resp.Code != 200 {
+		t.Fatalf("unable to create account: %d %v", resp.Code, resp.Body)
+	}
+
+	for _, it := range []string{`{"age": 30}`, `{"age": 40}`} {
+		resp = mockRequest(t, mux, http.MethodPatch, "/accounts/1", it)
+		if resp.Code != 200 {
+			t.Fatalf("unable to update account: %d %v", resp.Code, resp.Body)
+		}
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/accounts/1/versions", "")
+	items, _ := resp.Body["items"].([]any)
+
+	if resp.Code != 200 || len(items) != 3 || resp.Body["has_more"] != false {
+		t.Fatalf("unexpected revisions list: %d %v", resp.Code, resp.Body)
+	}
+
+	// newest first, numbered from 1
+	for idx, it := range items {
+		revision := it.(map[string]any)
+		if revision["revision"] != float64(3-idx) {
+			t.Errorf("unexpected revision order: %v", items)
+		}
+		if _, ok := revision["item"]; ok {
+			t.Errorf("snapshot should not be listed: %v", revision)
+		}
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/accounts/1/versions/1", "")
+	if resp.Code != 200 || resp.Body["action"] != string(AuditCreate) || resp.Body["item"].(map[string]any)["age"] != float64(20) {
+		t.Errorf("unexpected first revision: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/accounts/1/versions/9", "")
+	if resp.Code != 404 {
+		t.Errorf("missing revision should not be found: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/accounts/1/versions/abc", "")
+	if resp.Code != 400 {
+		t.Errorf("bad revision number should be rejected: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPost, "/accounts/1/versions/1/revert", "")
+	if resp.Code != 200 || resp.Body["item"].(map[string]any)["age"] != float64(20) {
+		t.Fatalf("unable to revert: %d %v", resp.Code, resp.Body)
+	}
+
+	// revert is a regular update, so it makes a new revision
+	resp = mockRequest(t, mux, http.MethodGet, "/accounts/1/versions/4", "")
+	if resp.Code != 200 || resp.Body["action"] != string(AuditUpdate) || resp.Body["item"].(map[string]any)["age"] != float64(20) {
+		t.Errorf("revert should be recorded as a new revision: %d %v", resp.Code, resp.Body)
+	}
+
+	// other model numbers its revisions separately
+	resp = mockRequest(t, mux, http.MethodPost, "/notes", `{"text": "note"}`)
+	if resp.Code != 200 {
+		t.Fatalf("unable to create note: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/notes/1/versions", "")
+	if items, _ := resp.Body["items"].([]any); resp.Code != 200 || len(items) != 1 {
+		t.Errorf("unexpected note revisions: %d %v", resp.Code, resp.Body)
+	}
+}
+
+func TestRevertHiddenFields(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockSecret{})
+
+	crud := New(group, ServeMuxRouter(mux).Group("/secrets"), MockSecret{}).Revisions()
+	crud.Generate()
+
+	app := group.Ctx
+
+	resp := mockRequest(t, mux, http.MethodPost, "/secrets", `{"label": "a", "pin": "1111"}`)
+	if resp.Code != 200 {
+		t.Fatalf("unable to create secret: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPatch, "/secrets/1", `{"label": "b", "pin": "2222"}`)
+	if resp.Code != 200 {
+		t.Fatalf("unable to update secret: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPost, "/secrets/1/versions/1/revert", "")
+	if resp.Code != 200 {
+		t.Fatalf("unable to revert: %d %v", resp.Code, resp.Body)
+	}
+
+	var secret MockSecret
+	app.Db.Raw().First(&secret, 1)
+
+	if secret.Label != "a" || secret.Pin != "1111" {
+		t.Errorf("hidden field should be restored too: %+v", secret)
+	}
+
+	// revisions stored without fill values can't restore hidden fields
+	app.Db.Raw().Table(crud.revisionsTable()).Where("revision = ?", 2).Update("fill_values", "")
+
+	resp = mockRequest(t, mux, http.MethodPost, "/secrets/1/versions/2/revert", "")
+	fields, _ := resp.Body["fields"].([]any)
+
+	if resp.Code != 409 || len(fields) != 1 || fields[0] != "pin" {
+		t.Errorf("partial revert should be rejected: %d %v", resp.Code, resp.Body)
+	}
+}
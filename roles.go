@@ -1,2 +1,197 @@
 package simpleapi
 
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// named role, could be referenced in `role` tags by name.
+// a role passes every check its inherited roles pass
+type Role struct {
+	Name string
+
+	// RequestData.RoleGroup value that maps to this role, 0 if none
+	Group uint8
+
+	Inherits []string
+}
+
+var (
+	// guards registeredRoles and roleByGroup, roles could be registered while serving
+	rolesMu sync.RWMutex
+
+	registeredRoles = map[string]Role{}
+	roleByGroup     = map[uint8]string{}
+)
+
+// registers a role globally, usually done on setup
+func RegisterRole(role Role) {
+
+	if role.Name == "" {
+		panic("role should have a name")
+	}
+
+	rolesMu.Lock()
+	defer rolesMu.Unlock()
+
+	registeredRoles[role.Name] = role
+
+	if role.Group > 0 {
+		roleByGroup[role.Group] = role.Name
+	}
+}
+
+func GetRole(name string) (Role, bool) {
+
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+
+	role, ok := registeredRoles[name]
+	return role, ok
+}
+
+// name of the role registered for a group
+func roleOfGroup(group uint8) (string, bool) {
+
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+
+	name, ok := roleByGroup[group]
+	return name, ok
+}
+
+// registered roles sorted by name
+func registeredRoleList() []Role {
+
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+
+	roles := []Role{}
+	for _, it := range registeredRoles {
+		roles = append(roles, it)
+	}
+
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return roles
+}
+
+// checks if role is the required one or inherits it through the hierarchy
+func RoleInherits(role string, required string) bool {
+
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+
+	return roleInherits(role, required, map[string]bool{})
+}
+
+func roleInherits(role string, required string, visited map[string]bool) bool {
+
+	if role == required {
+		return true
+	}
+
+	if visited[role] {
+		return false
+	}
+	visited[role] = true
+
+	for _, parent := range registeredRoles[role].Inherits {
+		if roleInherits(parent, required, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// name of the request role, explicit Role takes precedence over RoleGroup
+func (r RequestData) RoleName() string {
+
+	if r.Role != "" {
+		return r.Role
+	}
+
+	name, _ := roleOfGroup(r.RoleGroup)
+	return name
+}
+
+func (r RequestData) HasRole(required string) bool {
+
+	roleName := r.RoleName()
+
+	if roleName == "" {
+		return false
+	}
+
+	return RoleInherits(roleName, required)
+}
+
+// role requirement declared in a `role` tag, either by group number or by name
+func roleAllows(group uint64, name string, req RequestData) bool {
+
+	if group == 0 && name == "" {
+		return true
+	}
+
+	required := name
+
+	if required == "" {
+
+		registered := false
+
+		if group <= 255 {
+			required, registered = roleOfGroup(uint8(group))
+		}
+
+		// plain group numbers without registered roles must match exactly
+		if !registered {
+			return group == uint64(req.RoleGroup)
+		}
+	}
+
+	return req.HasRole(required)
+}
+
+// parses a single part of `role:"write,read"` tag
+func parseRoleDecl(decl string) (group uint64, name string) {
+
+	decl = strings.TrimSpace(decl)
+
+	if decl == "" {
+		return 0, ""
+	}
+
+	group, err := strconv.ParseUint(decl, 10, 64)
+	if err != nil {
+		return 0, decl
+	}
+
+	return group, ""
+}
+
+// role required by a declaration, registered role name if any, group number otherwise.
+// empty when no role is required
+func roleDeclString(group uint64, name string) string {
+
+	if name != "" {
+		return name
+	}
+
+	if group == 0 {
+		return ""
+	}
+
+	if group <= 255 {
+		registered, ok := roleOfGroup(uint8(group))
+		if ok {
+			return registered
+		}
+	}
+
+	return strconv.FormatUint(group, 10)
+}
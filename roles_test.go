@@ -0,0 +1,84 @@
+package simpleapi
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// roles registered by a test are dropped once it is finished
+func resetRolesOnCleanup(t *testing.T) {
+
+	rolesMu.Lock()
+	defer rolesMu.Unlock()
+
+	roles := registeredRoles
+	groups := roleByGroup
+
+	registeredRoles = map[string]Role{}
+	roleByGroup = map[uint8]string{}
+
+	for k, v := range roles {
+		registeredRoles[k] = v
+	}
+	for k, v := range groups {
+		roleByGroup[k] = v
+	}
+
+	t.Cleanup(func() {
+		rolesMu.Lock()
+		defer rolesMu.Unlock()
+
+		registeredRoles = roles
+		roleByGroup = groups
+	})
+}
+
+func TestRoleHierarchy(t *testing.T) {
+
+	resetRolesOnCleanup(t)
+
+	RegisterRole(Role{Name: "editor"})
+	RegisterRole(Role{Name: "moderator", Group: 2, Inherits: []string{"editor"}})
+	RegisterRole(Role{Name: "superadmin", Group: 3, Inherits: []string{"moderator"}})
+
+	fields := GetFieldTags[MockAppContext, MockArticle](MockArticle{})
+	appCtx := AppContext[MockAppContext]{}
+
+	input := gjson.Parse(`{"title":"t","featured":true,"note":"n"}`)
+
+	{
+		var article MockArticle
+
+		appCtx.FillEntityFromDto(fields, &article, input, nil, RequestData{RoleGroup: 3})
+
+		if !article.Featured || article.Note != "n" {
+			t.Errorf("superadmin should inherit moderator and editor rights: %#+v", article)
+		}
+	}
+
+	{
+		var article MockArticle
+
+		appCtx.FillEntityFromDto(fields, &article, input, nil, RequestData{Role: "editor"})
+
+		if !article.Featured || article.Note != "" {
+			t.Errorf("editor should write only editor fields: %#+v", article)
+		}
+	}
+
+	{
+		var article MockArticle
+
+		appCtx.FillEntityFromDto(fields, &article, input, nil, RequestData{})
+
+		if article.Featured || article.Note != "" || article.Title != "t" {
+			t.Errorf("anonymous request should write only public fields: %#+v", article)
+		}
+	}
+
+	dto := fields.ToDto(MockArticle{Note: "n"}, RequestData{Role: "editor"})
+	if _, leaked := dto["note"]; leaked {
+		t.Errorf("moderator field leaked to editor: %v", dto)
+	}
+}
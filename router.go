@@ -0,0 +1,141 @@
+package simpleapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Request is a single http request as seen by crud handlers,
+// implemented by router adapters, see GinRouter and ServeMuxRouter
+type Request interface {
+	// underlying net/http request
+	HttpRequest() *http.Request
+	Writer() http.ResponseWriter
+
+	// route param, declared as `:name` in paths
+	Param(name string) string
+	Query(name string) string
+	GetHeader(name string) string
+
+	// reads the whole body, the body could be read again after the call
+	Body() ([]byte, error)
+
+	// values shared by handlers of the same request
+	Get(key string) (any, bool)
+	Set(key string, value any)
+
+	Header(name string, value string)
+	JSON(code int, obj any)
+	Data(code int, contentType string, data []byte)
+
+	// response status written so far, 200 if nothing is written yet
+	Status() int
+	// keeps a copy of the response body written by the following handlers
+	Record() *bytes.Buffer
+
+	// runs pending handlers of the chain, used by middlewares acting after them
+	Next()
+	Abort()
+	AbortWithStatusJSON(code int, obj any)
+	IsAborted() bool
+}
+
+type HandlerFunc func(req Request)
+
+// Router registers handlers, paths use `:name` params regardless of the adapter.
+// handlers passed to Use are applied to routes registered after the call
+type Router interface {
+	Group(path string, handlers ...HandlerFunc) Router
+	Use(handlers ...HandlerFunc)
+	Handle(method string, path string, handlers ...HandlerFunc)
+	BasePath() string
+}
+
+// reads request body and puts a copy back, so it could be read again
+func readBody(req *http.Request) ([]byte, error) {
+
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+
+	return data, nil
+}
+
+// fills struct fields by `form` tags, empty values keep zero ones.
+// fails on the first value that could not be parsed
+func bindQuery(values url.Values, dest any) error {
+
+	reflected := reflect.ValueOf(dest).Elem()
+	typ := reflected.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+
+		tag, ok := typ.Field(i).Tag.Lookup("form")
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+
+		field := reflected.Field(i)
+
+		var err error
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Bool:
+			var parsed bool
+			parsed, err = strconv.ParseBool(value)
+			field.SetBool(parsed)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var parsed int64
+			parsed, err = strconv.ParseInt(value, 10, field.Type().Bits())
+			field.SetInt(parsed)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var parsed uint64
+			parsed, err = strconv.ParseUint(value, 10, field.Type().Bits())
+			field.SetUint(parsed)
+		}
+
+		if err != nil {
+			return fmt.Errorf("bad `%s` value: %s", name, value)
+		}
+	}
+
+	return nil
+}
+
+// list params of request, responds with 400 if some could not be parsed
+func bindListQuery(req Request) (params ListQueryParams, ok bool) {
+
+	err := bindQuery(req.HttpRequest().URL.Query(), &params)
+	if err != nil {
+		req.JSON(400, HM{
+			"msg": "bad query params",
+			"err": err.Error(),
+		})
+		return params, false
+	}
+
+	return params, true
+}
@@ -0,0 +1,167 @@
+package simpleapi
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ginRouter struct {
+	group *gin.RouterGroup
+}
+
+// GinRouter mounts cruds on a gin group, use `&engine.RouterGroup` for the engine root
+func GinRouter(group *gin.RouterGroup) Router {
+	return &ginRouter{group: group}
+}
+
+func ginHandlers(handlers []HandlerFunc) []gin.HandlerFunc {
+
+	result := make([]gin.HandlerFunc, 0, len(handlers))
+
+	for _, it := range handlers {
+		h := it
+		result = append(result, func(ctx *gin.Context) {
+			h(&ginRequest{ctx: ctx})
+		})
+	}
+
+	return result
+}
+
+func (r *ginRouter) Group(path string, handlers ...HandlerFunc) Router {
+	return &ginRouter{group: r.group.Group(path, ginHandlers(handlers)...)}
+}
+
+func (r *ginRouter) Use(handlers ...HandlerFunc) {
+	r.group.Use(ginHandlers(handlers)...)
+}
+
+func (r *ginRouter) Handle(method string, path string, handlers ...HandlerFunc) {
+	r.group.Handle(method, path, ginHandlers(handlers)...)
+}
+
+func (r *ginRouter) BasePath() string {
+	return r.group.BasePath()
+}
+
+type ginRequest struct {
+	ctx *gin.Context
+}
+
+// GinContext returns gin context of a request served by GinRouter
+func GinContext(req Request) (*gin.Context, bool) {
+
+	ginReq, ok := req.(*ginRequest)
+	if !ok {
+		return nil, false
+	}
+
+	return ginReq.ctx, true
+}
+
+// GinHandler adapts gin middleware, e.g. for UseExisting, works only with GinRouter
+func GinHandler(h gin.HandlerFunc) HandlerFunc {
+	return func(req Request) {
+
+		ctx, ok := GinContext(req)
+		if !ok {
+			panic("gin handler is used with a non gin router")
+		}
+
+		h(ctx)
+	}
+}
+
+func (r *ginRequest) HttpRequest() *http.Request {
+	return r.ctx.Request
+}
+
+func (r *ginRequest) Writer() http.ResponseWriter {
+	return r.ctx.Writer
+}
+
+func (r *ginRequest) Param(name string) string {
+	return r.ctx.Param(name)
+}
+
+func (r *ginRequest) Query(name string) string {
+	return r.ctx.Query(name)
+}
+
+func (r *ginRequest) GetHeader(name string) string {
+	return r.ctx.GetHeader(name)
+}
+
+func (r *ginRequest) Body() ([]byte, error) {
+	return readBody(r.ctx.Request)
+}
+
+func (r *ginRequest) Get(key string) (any, bool) {
+	return r.ctx.Get(key)
+}
+
+func (r *ginRequest) Set(key string, value any) {
+	r.ctx.Set(key, value)
+}
+
+func (r *ginRequest) Header(name string, value string) {
+	r.ctx.Header(name, value)
+}
+
+func (r *ginRequest) JSON(code int, obj any) {
+	r.ctx.JSON(code, obj)
+}
+
+func (r *ginRequest) Data(code int, contentType string, data []byte) {
+	r.ctx.Data(code, contentType, data)
+}
+
+func (r *ginRequest) Status() int {
+	return r.ctx.Writer.Status()
+}
+
+// response writer keeping a copy of the body
+type ginRecordingWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *ginRecordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *ginRecordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (r *ginRequest) Record() *bytes.Buffer {
+
+	writer := &ginRecordingWriter{
+		ResponseWriter: r.ctx.Writer,
+		body:           &bytes.Buffer{},
+	}
+
+	r.ctx.Writer = writer
+
+	return writer.body
+}
+
+func (r *ginRequest) Next() {
+	r.ctx.Next()
+}
+
+func (r *ginRequest) Abort() {
+	r.ctx.Abort()
+}
+
+func (r *ginRequest) AbortWithStatusJSON(code int, obj any) {
+	r.ctx.AbortWithStatusJSON(code, obj)
+}
+
+func (r *ginRequest) IsAborted() bool {
+	return r.ctx.IsAborted()
+}
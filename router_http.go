@@ -0,0 +1,239 @@
+package simpleapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type muxRouter struct {
+	mux      *http.ServeMux
+	basePath string
+	handlers []HandlerFunc
+}
+
+// ServeMuxRouter mounts cruds on a net/http mux, routes are registered
+// with method and wildcard patterns of go 1.22
+func ServeMuxRouter(mux *http.ServeMux) Router {
+	return &muxRouter{
+		mux:      mux,
+		basePath: "/",
+	}
+}
+
+// copy of base handlers with extra ones appended
+func combineHandlers(base []HandlerFunc, extra []HandlerFunc) []HandlerFunc {
+
+	result := append([]HandlerFunc{}, base...)
+
+	return append(result, extra...)
+}
+
+func joinPaths(base string, relative string) string {
+
+	if relative == "" {
+		return base
+	}
+
+	joined := path.Join(base, relative)
+
+	if strings.HasSuffix(relative, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+
+	return joined
+}
+
+var muxWildcardRe = regexp.MustCompile(`([:*])([A-Za-z0-9_]+)`)
+
+// `/items/:id/*rest` -> `/items/{id}/{rest...}`
+func muxPattern(routePath string) string {
+	return muxWildcardRe.ReplaceAllStringFunc(routePath, func(s string) string {
+		if s[0] == '*' {
+			return "{" + s[1:] + "...}"
+		}
+		return "{" + s[1:] + "}"
+	})
+}
+
+func (r *muxRouter) Group(path string, handlers ...HandlerFunc) Router {
+	return &muxRouter{
+		mux:      r.mux,
+		basePath: joinPaths(r.basePath, path),
+		handlers: combineHandlers(r.handlers, handlers),
+	}
+}
+
+func (r *muxRouter) Use(handlers ...HandlerFunc) {
+	r.handlers = append(r.handlers, handlers...)
+}
+
+func (r *muxRouter) Handle(method string, path string, handlers ...HandlerFunc) {
+
+	chain := combineHandlers(r.handlers, handlers)
+
+	r.mux.HandleFunc(method+" "+muxPattern(joinPaths(r.basePath, path)), func(w http.ResponseWriter, req *http.Request) {
+
+		muxReq := &muxRequest{
+			writer:   &muxWriter{ResponseWriter: w},
+			req:      req,
+			handlers: chain,
+			index:    -1,
+		}
+
+		muxReq.Next()
+	})
+}
+
+func (r *muxRouter) BasePath() string {
+	return r.basePath
+}
+
+// keeps response status and optionally a copy of the body
+type muxWriter struct {
+	http.ResponseWriter
+
+	status int
+	record *bytes.Buffer
+}
+
+func (w *muxWriter) WriteHeader(code int) {
+
+	if w.status != 0 {
+		return
+	}
+
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *muxWriter) Write(data []byte) (int, error) {
+
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.record != nil {
+		w.record.Write(data)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+// used by http.ResponseController
+func (w *muxWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+const muxAbortIndex = math.MaxInt32
+
+type muxRequest struct {
+	writer *muxWriter
+	req    *http.Request
+
+	handlers []HandlerFunc
+	index    int
+
+	keys map[string]any
+}
+
+func (r *muxRequest) HttpRequest() *http.Request {
+	return r.req
+}
+
+func (r *muxRequest) Writer() http.ResponseWriter {
+	return r.writer
+}
+
+func (r *muxRequest) Param(name string) string {
+	return r.req.PathValue(name)
+}
+
+func (r *muxRequest) Query(name string) string {
+	return r.req.URL.Query().Get(name)
+}
+
+func (r *muxRequest) GetHeader(name string) string {
+	return r.req.Header.Get(name)
+}
+
+func (r *muxRequest) Body() ([]byte, error) {
+	return readBody(r.req)
+}
+
+func (r *muxRequest) Get(key string) (any, bool) {
+	value, ok := r.keys[key]
+	return value, ok
+}
+
+func (r *muxRequest) Set(key string, value any) {
+
+	if r.keys == nil {
+		r.keys = map[string]any{}
+	}
+
+	r.keys[key] = value
+}
+
+func (r *muxRequest) Header(name string, value string) {
+	r.writer.Header().Set(name, value)
+}
+
+func (r *muxRequest) JSON(code int, obj any) {
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		http.Error(r.writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	r.Data(code, "application/json; charset=utf-8", data)
+}
+
+func (r *muxRequest) Data(code int, contentType string, data []byte) {
+	r.writer.Header().Set("Content-Type", contentType)
+	r.writer.WriteHeader(code)
+	r.writer.Write(data)
+}
+
+func (r *muxRequest) Status() int {
+
+	if r.writer.status == 0 {
+		return http.StatusOK
+	}
+
+	return r.writer.status
+}
+
+func (r *muxRequest) Record() *bytes.Buffer {
+	r.writer.record = &bytes.Buffer{}
+	return r.writer.record
+}
+
+// same semantics as gin: handlers run in order until one of them aborts
+func (r *muxRequest) Next() {
+
+	r.index++
+
+	for r.index < len(r.handlers) {
+		r.handlers[r.index](r)
+		r.index++
+	}
+}
+
+func (r *muxRequest) Abort() {
+	r.index = muxAbortIndex
+}
+
+func (r *muxRequest) AbortWithStatusJSON(code int, obj any) {
+	r.Abort()
+	r.JSON(code, obj)
+}
+
+func (r *muxRequest) IsAborted() bool {
+	return r.index >= muxAbortIndex
+}
@@ -0,0 +1,131 @@
+package simpleapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServeMuxRouter(t *testing.T) {
+
+	mux := http.NewServeMux()
+	router := ServeMuxRouter(mux)
+
+	items := router.Group("/users/:user/items")
+
+	items.Use(func(req Request) {
+		if req.GetHeader("Authorization") == "" {
+			req.AbortWithStatusJSON(403, HM{"msg": "forbidden"})
+			return
+		}
+		req.Set("user", req.Param("user"))
+	})
+
+	items.Handle(http.MethodGet, "/:id", func(req Request) {
+
+		params, ok := bindListQuery(req)
+		if !ok {
+			return
+		}
+
+		req.JSON(200, HM{
+			"user": MustGetObjectFromContext[string](req, "user"),
+			"id":   req.Param("id"),
+			"page": params.Page,
+		})
+	})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/4/items/9", nil))
+
+	if w.Code != 403 {
+		t.Errorf("middleware should abort the chain, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/4/items/9?page=2", nil)
+	req.Header.Set("Authorization", "token")
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != 200 || w.Body.String() != `{"id":"9","page":2,"user":"4"}` {
+		t.Errorf("unexpected response %d: %s", w.Code, w.Body.String())
+	}
+
+	if items.BasePath() != "/users/:user/items" {
+		t.Errorf("unexpected base path %s", items.BasePath())
+	}
+}
+
+func TestServeMuxGeneratedCrud(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockNote{})
+
+	// every optional route of a crud is registered without pattern conflicts
+	New(group, ServeMuxRouter(mux).Group("/notes"), MockNote{}).
+		Revisions().
+		Idempotent(time.Hour).
+		Generate()
+
+	text := func(resp mockResponse, key string) any {
+		obj, _ := resp.Body[key].(map[string]any)
+		return obj["text"]
+	}
+
+	// body is read by idempotency middleware and by create handler
+	created := mockRequest(t, mux, http.MethodPost, "/notes", `{"text": "hello"}`, IdempotencyKeyHeader+": k1")
+	if created.Code != 200 || text(created, "object") != "hello" {
+		t.Fatalf("unable to create note: %d %v", created.Code, created.Body)
+	}
+
+	replayed := mockRequest(t, mux, http.MethodPost, "/notes", `{"text": "hello"}`, IdempotencyKeyHeader+": k1")
+	if replayed.Header.Get("Idempotent-Replayed") != "true" || text(replayed, "object") != "hello" {
+		t.Errorf("retry should be replayed: %d %v", replayed.Code, replayed.Body)
+	}
+
+	resp := mockRequest(t, mux, http.MethodPost, "/notes/bulk", `[{"text": "second"}, {"text": "third"}]`)
+	if resp.Code != 200 || resp.Body["created"] != float64(2) {
+		t.Errorf("bulk create failed: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/notes", "")
+	if items, _ := resp.Body["items"].([]any); resp.Code != 200 || len(items) != 3 {
+		t.Errorf("unexpected list: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/notes?page=abc", "")
+	if resp.Code != 400 {
+		t.Errorf("bad query param should be rejected: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPatch, "/notes/1", `{"text": "edited"}`)
+	if resp.Code != 200 || text(resp, "item") != "edited" {
+		t.Errorf("update failed: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPost, "/notes/1/versions/1/revert", "")
+	if resp.Code != 200 || text(resp, "item") != "hello" {
+		t.Errorf("revert failed: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodDelete, "/notes/1", "")
+	if resp.Code != 200 {
+		t.Errorf("removal failed: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/notes/trash", "", "X-Admin: 1")
+	if items, _ := resp.Body["items"].([]any); resp.Code != 200 || len(items) != 1 {
+		t.Errorf("unexpected trash: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/notes/trash?per_page=many", "", "X-Admin: 1")
+	if resp.Code != 400 {
+		t.Errorf("bad query param should be rejected: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPost, "/notes/1/restore", "")
+	if resp.Code != 200 || text(resp, "item") != "hello" {
+		t.Errorf("restore failed: %d %v", resp.Code, resp.Body)
+	}
+}
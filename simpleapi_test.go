@@ -37,6 +37,34 @@ func testToDto(t *testing.T) {
 	t.Logf(" dto : %s", jDto)
 }
 
+func testReadRole(t *testing.T) {
+
+	report := MockReport{
+		Id:     1,
+		Title:  "q1",
+		Notes:  "moderators only",
+		Salary: 100,
+	}
+
+	fields := GetFieldTags[MockAppContext, MockReport](report)
+
+	userDto := fields.ToDto(report, RequestData{RoleGroup: 0})
+
+	if _, leaked := userDto["notes"]; leaked {
+		t.Errorf("read role restricted field leaked: %v", userDto)
+	}
+
+	if _, ok := userDto["title"]; !ok {
+		t.Errorf("public field is missing: %v", userDto)
+	}
+
+	moderatorDto := fields.ToDto(report, RequestData{RoleGroup: 2})
+
+	if moderatorDto["notes"] != "moderators only" || moderatorDto["salary"] != uint64(100) {
+		t.Errorf("read role field is hidden from allowed role: %v", moderatorDto)
+	}
+}
+
 // func testWriteProtectedField(t *testing.T) {
 
 // 	user := types.User{
@@ -125,5 +153,6 @@ func testToDto(t *testing.T) {
 func TestSimpleapi(t *testing.T) {
 
 	testToDto(t)
+	testReadRole(t)
 	// testWriteProtectedField(t)
 }
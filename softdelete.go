@@ -0,0 +1,199 @@
+package simpleapi
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SoftDeleteKind int
+
+const (
+	// numeric or bool column, set to 1 when removed
+	SoftDeleteFlag SoftDeleteKind = iota
+	// time.Time or *time.Time column, removal time or NULL
+	SoftDeleteTime
+	// gorm.DeletedAt column, gorm scopes are bypassed, conditions are managed here
+	SoftDeleteGorm
+)
+
+type SoftDeleteInfo struct {
+	UserReferenceInfo
+
+	Kind SoftDeleteKind
+
+	// optional `simpleapi:"deletedby"` field stamped with authorized user id
+	DeletedBy UserReferenceInfo
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	gormDeletedType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+func softDeleteKindOf(typ reflect.Type) SoftDeleteKind {
+
+	switch {
+	case typ == gormDeletedType:
+		return SoftDeleteGorm
+	case typ == timeType, typ.Kind() == reflect.Pointer && typ.Elem() == timeType:
+		return SoftDeleteTime
+	default:
+		return SoftDeleteFlag
+	}
+}
+
+// sql condition selecting removed (or not removed) rows
+func (s SoftDeleteInfo) Condition(tableName string, fieldType reflect.Type, deleted bool) (string, []any) {
+
+	column := s.TableColumnName
+	if tableName != "" {
+		column = fmt.Sprintf("%s.%s", tableName, column)
+	}
+
+	switch s.Kind {
+	case SoftDeleteTime, SoftDeleteGorm:
+
+		// non pointer time could be stored as zero time instead of NULL
+		if fieldType == timeType {
+			if deleted {
+				return fmt.Sprintf("(%s IS NOT NULL AND %s != ?)", column, column), []any{time.Time{}}
+			}
+			return fmt.Sprintf("(%s IS NULL OR %s = ?)", column, column), []any{time.Time{}}
+		}
+
+		if deleted {
+			return fmt.Sprintf("%s IS NOT NULL", column), []any{}
+		}
+		return fmt.Sprintf("%s IS NULL", column), []any{}
+
+	default:
+		if deleted {
+			return fmt.Sprintf("%s != ?", column), []any{0}
+		}
+		return fmt.Sprintf("%s = ?", column), []any{0}
+	}
+}
+
+// flag columns could only be bool or integer
+func softDeleteTypeSupported(kind SoftDeleteKind, fieldType reflect.Type) bool {
+
+	if kind != SoftDeleteFlag {
+		return true
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// value that marks object as removed, field type is checked by New
+func softDeletedValue(kind SoftDeleteKind, fieldType reflect.Type, now time.Time) reflect.Value {
+
+	switch kind {
+	case SoftDeleteGorm:
+		return reflect.ValueOf(gorm.DeletedAt{Time: now, Valid: true})
+	case SoftDeleteTime:
+		if fieldType.Kind() == reflect.Pointer {
+			return reflect.ValueOf(&now)
+		}
+		return reflect.ValueOf(now)
+	}
+
+	result := reflect.New(fieldType).Elem()
+
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		result.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		result.SetUint(1)
+	default:
+		panic(fmt.Sprintf("unsupported soft delete field type: %s", fieldType))
+	}
+
+	return result
+}
+
+// db handle for crud queries. gorm soft delete scope is disabled,
+// removed rows are filtered by crud conditions instead
+func (result *CrudConfig[T, CtxType]) rawDb(appctx *AppContext[CtxType]) *gorm.DB {
+
+	softDeleteField := result.TypeDataModel.SoftDeleteField
+
+	if softDeleteField.Has && softDeleteField.Kind == SoftDeleteGorm {
+		return appctx.Db.Raw().Unscoped()
+	}
+
+	return appctx.Db.Raw()
+}
+
+func (result *CrudConfig[T, CtxType]) softDeleteCondition(deleted bool) (string, []any) {
+
+	softDeleteField := result.TypeDataModel.SoftDeleteField
+	fieldType := result.TypeDataModel.Fields[softDeleteField.DeclName].NativeType
+
+	return softDeleteField.Condition(result.tableName, fieldType, deleted)
+}
+
+// marks object as removed or restores it, returns columns to update
+func (result *CrudConfig[T, CtxType]) stampSoftDelete(obj *T, deleted bool, reqData RequestData) ([]string, error) {
+
+	softDeleteField := result.TypeDataModel.SoftDeleteField
+
+	reflected := reflect.ValueOf(obj).Elem()
+
+	field := reflected.FieldByName(softDeleteField.DeclName)
+
+	if deleted {
+		field.Set(softDeletedValue(softDeleteField.Kind, field.Type(), time.Now()))
+	} else {
+		field.Set(reflect.Zero(field.Type()))
+	}
+
+	columns := []string{softDeleteField.TableColumnName}
+
+	deletedBy := softDeleteField.DeletedBy
+
+	if deletedBy.Has {
+
+		if deleted && reqData.AuthorizedUserId != nil {
+			err := setFieldValue(obj, deletedBy.DeclName, result.TypeDataModel.Fields[deletedBy.DeclName], reqData.AuthorizedUserId, reqData)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			byField := reflected.FieldByName(deletedBy.DeclName)
+			byField.Set(reflect.Zero(byField.Type()))
+		}
+
+		columns = append(columns, deletedBy.TableColumnName)
+	}
+
+	return columns, nil
+}
+
+// truthiness of a filter value passed as json
+func isTruthy(value any) bool {
+
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != "" && v != "0" && v != "false"
+	default:
+		return true
+	}
+}
@@ -0,0 +1,32 @@
+package simpleapi
+
+// fills `simpleapi:"createdby"` and `simpleapi:"updatedby"` fields with authorized user id.
+// created by is stamped only for new objects
+func (result *CrudConfig[T, CtxType]) stampAuthor(obj *T, created bool, reqData RequestData) error {
+
+	if reqData.AuthorizedUserId == nil {
+		return nil
+	}
+
+	stamped := []UserReferenceInfo{result.TypeDataModel.UpdatedByField}
+
+	if created {
+		stamped = append(stamped, result.TypeDataModel.CreatedByField)
+	}
+
+	for _, it := range stamped {
+
+		if !it.Has {
+			continue
+		}
+
+		err := setFieldValue(obj, it.DeclName, result.TypeDataModel.Fields[it.DeclName], reqData.AuthorizedUserId, reqData)
+		if err != nil {
+			return err
+		}
+
+		reqData.log_format(" stamped author field `%s` with %v", it.FillName, reqData.AuthorizedUserId)
+	}
+
+	return nil
+}
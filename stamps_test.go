@@ -0,0 +1,32 @@
+package simpleapi
+
+import "testing"
+
+func TestAuthorStamps(t *testing.T) {
+
+	fields := GetFieldTags[MockAppContext, MockTicket](MockTicket{})
+
+	if fields.Fields["AuthorId"].Fillable || fields.Fields["EditorId"].Fillable {
+		t.Errorf("author fields should not be fillable")
+	}
+
+	crud := &CrudConfig[MockTicket, MockAppContext]{
+		TypeDataModel: fields,
+	}
+
+	ticket := MockTicket{}
+
+	if err := crud.stampAuthor(&ticket, true, RequestData{AuthorizedUserId: 3}); err != nil {
+		t.Fatalf("unable to stamp: %s", err.Error())
+	}
+
+	if ticket.AuthorId != 3 || ticket.EditorId == nil || *ticket.EditorId != 3 {
+		t.Errorf("both author fields should be stamped on create, got %+v", ticket)
+	}
+
+	crud.stampAuthor(&ticket, false, RequestData{AuthorizedUserId: 5})
+
+	if ticket.AuthorId != 3 || *ticket.EditorId != 5 {
+		t.Errorf("only updated by should be stamped on update, got %+v", ticket)
+	}
+}
@@ -0,0 +1,91 @@
+package simpleapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// app backed by a file sqlite database in a temp dir, models are migrated and registered
+func mockDbApp(t *testing.T, models ...any) *AppContext[MockAppContext] {
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000"
+
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("unable to open test db: %s", err.Error())
+	}
+
+	app := NewAppContext(&MockAppContext{})
+	app.Db = WrapGormDb(db, app)
+	app.Db.MigrateAll(models...)
+
+	return app
+}
+
+// crud group mounted on a ServeMux, `X-User` header sets authorized user, `X-Admin` grants admin
+func mockDbGroup(t *testing.T, models ...any) (*CrudGroup[MockAppContext], *http.ServeMux) {
+
+	app := mockDbApp(t, models...)
+
+	group := NewCrudGroup(*app, CrudGroupConfig[MockAppContext]{
+		ObjectIdFieldName: "id",
+		RequestDataGenerator: func(req Request, ctx *AppContext[MockAppContext]) RequestData {
+
+			reqData := RequestData{
+				IsAdmin: req.GetHeader("X-Admin") != "",
+			}
+
+			if user := req.GetHeader("X-User"); user != "" {
+				reqData.AuthorizedUserId = user
+			}
+
+			return reqData
+		},
+	})
+
+	return group, http.NewServeMux()
+}
+
+type mockResponse struct {
+	Code   int
+	Header http.Header
+	Body   HM
+}
+
+// sends a request with optional headers as `name: value` pairs
+func mockRequest(t *testing.T, handler http.Handler, method string, path string, body string, headers ...string) mockResponse {
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+
+	for _, it := range headers {
+		name, value, _ := strings.Cut(it, ":")
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := mockResponse{
+		Code:   w.Code,
+		Header: w.Header(),
+		Body:   HM{},
+	}
+
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp.Body); err != nil {
+			t.Fatalf("%s %s: bad json response `%s`", method, path, w.Body.String())
+		}
+	}
+
+	return resp
+}
@@ -32,6 +32,8 @@ func ToDto[T any, CtxType any](it T, appctx *AppContext[CtxType], req RequestDat
 
 		if internalError != nil {
 			return typed.ResultFailed[map[string]any](internalError)
+		} else if result.IsOk() {
+			return typed.ResultOk(req.pruneDto(result.Unwrap()))
 		} else {
 			return result
 		}
@@ -0,0 +1,168 @@
+package simpleapi
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// RestoreEntity brings soft removed object back
+func (result *CrudConfig[T, CtxType]) RestoreEntity(appctx *AppContext[CtxType], modelCopy T, reqData RequestData) (objectRestored T, respData *RespErr) {
+
+	softDeleteField := result.TypeDataModel.SoftDeleteField
+
+	if !softDeleteField.Has {
+		respData = NewRespErr(404, HM{
+			"msg": "object can't be restored",
+		})
+		return
+	}
+
+	reqData.log_format("restoring soft removed field : %s", softDeleteField.FillName)
+
+	original := modelCopy
+
+	columns, _ := result.stampSoftDelete(&modelCopy, false, reqData)
+
+	updateErr := appctx.DbTransaction(func(isolatedContext AppContext[CtxType]) error {
+
+		err := isolatedContext.Db.UpdateFields(&modelCopy, columns...)
+		if err != nil {
+			return err
+		}
+
+		return result.recordChange(&isolatedContext, AuditRestore, &original, &modelCopy, reqData)
+	})
+	if updateErr != nil {
+
+		responseData := HM{
+			"msg": "unable to restore",
+		}
+
+		if reqData.IsAdmin {
+			responseData["err"] = updateErr.Error()
+		}
+
+		respData = NewRespErr(500, responseData)
+		return
+	}
+
+	return modelCopy, NewRespErr(200, HM{
+		"ok":   true,
+		"item": ToDto(modelCopy, appctx, reqData).Unwrap(),
+	})
+}
+
+// PurgeEntity removes object from db permanently, even if it supports soft removal
+func (result *CrudConfig[T, CtxType]) PurgeEntity(appctx *AppContext[CtxType], modelCopy T, reqData RequestData) (respData *RespErr) {
+
+	respData = result.removeEntity(appctx, modelCopy, reqData, true)
+
+	if respData.Httpcode == 200 {
+		respData.Data["purged"] = true
+	}
+
+	return
+}
+
+// filter selecting soft removed objects only
+func (result *CrudConfig[T, CtxType]) trashFilter() HM {
+
+	// time based removal is selected by truthy value, see prepareFilterData
+	if result.TypeDataModel.SoftDeleteField.Kind != SoftDeleteFlag {
+		return HM{
+			result.TypeDataModel.SoftDeleteField.FillName: true,
+		}
+	}
+
+	return HM{
+		result.TypeDataModel.SoftDeleteField.FillName: HM{
+			"op": "ne",
+			"v":  0,
+		},
+	}
+}
+
+// ListTrash lists soft removed objects, admin only
+func (result *CrudConfig[T, CtxType]) ListTrash(appctx *AppContext[CtxType], listQueryParams ListQueryParams, reqData RequestData) *RespErr {
+
+	if !reqData.IsAdmin {
+		return NewRespErr(403, HM{
+			"msg": "admin only",
+		})
+	}
+
+	filtersMap := HM{}
+	json.Unmarshal([]byte(listQueryParams.Filter), &filtersMap)
+
+	for k, v := range result.trashFilter() {
+		filtersMap[k] = v
+	}
+
+	// removed objects of every user are listed unless admin narrows it down
+	userRef := result.TypeDataModel.UserReferenceField
+	if userRef.Has && filtersMap[userRef.FillName] == nil {
+		filtersMap[userRef.FillName] = "*"
+	}
+
+	encodedFilter, _ := json.Marshal(filtersMap)
+
+	listQueryParams.Filter = string(encodedFilter)
+	listQueryParams.PredefinedQuery = ""
+
+	return result.ListEntities(appctx, listQueryParams, reqData)
+}
+
+func isPurgeRequest(ctx Request) bool {
+	purge := ctx.Query("purge")
+	return purge == "1" || purge == "true"
+}
+
+func (result *CrudConfig[T, CtxType]) generateTrashEndpoints(group Router, writePermissionMiddleware HandlerFunc) {
+
+	if !result.TypeDataModel.SoftDeleteField.Has {
+		return
+	}
+
+	if !result.disableEndpoints.Delete {
+		group.Handle(http.MethodPost, "/:id/restore", writePermissionMiddleware, func(ctx Request) {
+
+			// restored object is searched among removed ones too
+			result.loadExisting(ctx, true)
+			if ctx.IsAborted() {
+				return
+			}
+
+			reqData := result.RequestData(ctx)
+
+			modelCopy := MustGetObjectFromContext[T](ctx, "_eobj")
+
+			_, restoreResp := result.RestoreEntity(result.App, modelCopy, reqData)
+
+			if reqData.Debug {
+				restoreResp.Data["logs"] = reqData.getDebugLogs()
+			}
+
+			ctx.JSON(restoreResp.Httpcode, restoreResp.Data)
+		})
+	}
+
+	if !result.disableEndpoints.List {
+		group.Handle(http.MethodGet, "/trash", func(ctx Request) {
+
+			reqData := result.RequestData(ctx)
+
+			listQueryParams, ok := bindListQuery(ctx)
+			if !ok {
+				return
+			}
+
+			listResp := result.ListTrash(result.App, listQueryParams, reqData)
+
+			if reqData.Debug {
+				listResp.Data["logs"] = reqData.getDebugLogs()
+			}
+
+			ctx.JSON(listResp.Httpcode, listResp.Data)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package simpleapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTrash(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockMemo{})
+
+	New(group, ServeMuxRouter(mux).Group("/memos"), MockMemo{}).Generate()
+
+	app := group.Ctx
+
+	for _, it := range []MockMemo{
+		{OwnerId: 1, Text: "first"},
+		{OwnerId: 2, Text: "second"},
+	} {
+		if err := app.Db.Create(&it); err != nil {
+			t.Fatalf("unable to create memo: %s", err.Error())
+		}
+	}
+
+	removed := func(id uint64) bool {
+		memo := MockMemo{}
+		if err := app.Db.Raw().First(&memo, id).Error; err != nil {
+			t.Fatalf("memo %d not found: %s", id, err.Error())
+		}
+		return memo.Removed
+	}
+
+	for id, user := range map[string]string{"1": "X-User: 1", "2": "X-User: 2"} {
+		resp := mockRequest(t, mux, http.MethodDelete, "/memos/"+id, "", user)
+		if resp.Code != 200 {
+			t.Fatalf("unable to remove memo %s: %d %v", id, resp.Code, resp.Body)
+		}
+	}
+
+	if !removed(1) || !removed(2) {
+		t.Fatalf("memos should be soft removed")
+	}
+
+	resp := mockRequest(t, mux, http.MethodGet, "/memos/trash", "", "X-User: 1")
+	if resp.Code != 403 {
+		t.Errorf("trash should be listed by admins only: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodGet, "/memos/trash", "", "X-Admin: 1")
+	if resp.Code != 200 || len(resp.Body["items"].([]any)) != 2 {
+		t.Errorf("admin should see removed memos: %d %v", resp.Code, resp.Body)
+	}
+
+	// someone else's memo
+	resp = mockRequest(t, mux, http.MethodPost, "/memos/2/restore", "", "X-User: 1")
+	if resp.Code != 404 || !removed(2) {
+		t.Errorf("non admin should not restore others memo: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodPost, "/memos/1/restore", "", "X-User: 1")
+	if resp.Code != 200 || removed(1) {
+		t.Errorf("owner should restore own memo: %d %v", resp.Code, resp.Body)
+	}
+
+	resp = mockRequest(t, mux, http.MethodDelete, "/memos/2?purge=true", "", "X-Admin: 1")
+	if resp.Code != 200 || resp.Body["purged"] != true || countRows[MockMemo](t, app) != 1 {
+		t.Errorf("purge should remove the row: %d %v", resp.Code, resp.Body)
+	}
+}
@@ -0,0 +1,385 @@
+package simpleapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var (
+	tsIdentRe      = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	openapiParamRe = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+)
+
+// property name, quoted when it is not a valid identifier
+func tsProp(name string) string {
+
+	if tsIdentRe.MatchString(name) {
+		return name
+	}
+
+	return fmt.Sprintf("%q", name)
+}
+
+// types of a json schema, `type` could be a single name or a list of names
+func schemaTypes(schema map[string]any) []string {
+
+	switch val := schema["type"].(type) {
+	case string:
+		return []string{val}
+	case []string:
+		return val
+	case []any:
+		types := []string{}
+		for _, it := range val {
+			if name, ok := it.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+
+	return nil
+}
+
+// typescript type of a json schema produced by typeSchema
+func tsType(schema map[string]any) string {
+
+	types := schemaTypes(schema)
+	if len(types) == 0 {
+		return "unknown"
+	}
+
+	result := []string{}
+
+	for _, it := range types {
+		switch it {
+		case "integer", "number":
+			result = append(result, "number")
+		case "string":
+			result = append(result, "string")
+		case "boolean":
+			result = append(result, "boolean")
+		case "null":
+			result = append(result, "null")
+		case "array":
+			items, _ := schema["items"].(map[string]any)
+			itemType := tsType(items)
+			if strings.Contains(itemType, " ") {
+				itemType = "(" + itemType + ")"
+			}
+			result = append(result, itemType+"[]")
+		default:
+			result = append(result, "Record<string, unknown>")
+		}
+	}
+
+	return strings.Join(result, " | ")
+}
+
+// `active_users` -> `ActiveUsers`
+func tsPascal(name string) string {
+
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := ""
+	for _, it := range parts {
+		result += strings.ToUpper(it[:1]) + it[1:]
+	}
+
+	return result
+}
+
+// value type of a filter operator, v is the field type
+func tsOperatorValue(op string) string {
+
+	switch op {
+	case "gt", "gte", "lt", "lte", "ne":
+		return "V"
+	case "in":
+		return "V[]"
+	case "lookup":
+		return "string"
+	default:
+		// custom operators registered with SetListFilterHandler
+		return "unknown"
+	}
+}
+
+// collection path as a template literal, `{param}` values are taken from `this.params`
+func tsPath(path string) (literal string, params []string) {
+
+	for _, it := range openapiParamRe.FindAllStringSubmatch(path, -1) {
+		params = append(params, it[1])
+	}
+
+	literal = openapiParamRe.ReplaceAllString(path, "$${encodeURIComponent(String(this.params.$1))}")
+
+	return "`" + literal + "`", params
+}
+
+const tsClientRuntime = `export class SimpleApiError extends Error {
+  constructor(readonly status: number, readonly body: any) {
+    super(body && typeof body.msg === "string" ? body.msg : ` + "`request failed with status ${status}`" + `);
+  }
+}
+
+export interface ClientOptions {
+  headers?: Record<string, string> | (() => Record<string, string> | Promise<Record<string, string>>);
+  fetch?: typeof fetch;
+}
+
+export interface ListResponse<T> {
+  items: T[];
+  has_more?: boolean;
+  pages?: number;
+  total_items?: number;
+  next_cursor?: string;
+  prev_cursor?: string;
+}
+
+export class SimpleApiClient {
+  constructor(readonly baseUrl: string, readonly options: ClientOptions = {}) {}
+
+  async request<R>(method: string, path: string, query?: Record<string, unknown>, body?: unknown): Promise<R> {
+    const search = new URLSearchParams();
+
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value === undefined || value === null) {
+        continue;
+      }
+      if (Array.isArray(value)) {
+        search.set(key, value.join(","));
+      } else if (typeof value === "object") {
+        search.set(key, JSON.stringify(value));
+      } else {
+        search.set(key, String(value));
+      }
+    }
+
+    let url = this.baseUrl.replace(/\/+$/, "") + path;
+    const qs = search.toString();
+    if (qs) {
+      url += "?" + qs;
+    }
+
+    const headers: Record<string, string> = { Accept: "application/json" };
+    const extra = typeof this.options.headers === "function" ? await this.options.headers() : this.options.headers;
+    Object.assign(headers, extra ?? {});
+
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+
+    const doFetch = this.options.fetch ?? fetch;
+    const resp = await doFetch(url, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+
+    const text = await resp.text();
+    const data = text ? JSON.parse(text) : {};
+
+    if (!resp.ok) {
+      throw new SimpleApiError(resp.status, data);
+    }
+
+    return data as R;
+  }
+}
+`
+
+// TypeScriptClient renders typed interfaces, filters and fetch based clients of described resources
+func TypeScriptClient(resources []ResourceDoc) string {
+
+	out := &strings.Builder{}
+
+	fmt.Fprintf(out, "// generated by simpleapi-gen, do not edit\n\n")
+
+	// operators are global, take a union in case docs come from different servers
+	opsSet := map[string]bool{}
+	for _, res := range resources {
+		for _, op := range res.FilterOperators {
+			opsSet[op] = true
+		}
+	}
+
+	ops := []string{}
+	for op := range opsSet {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	fmt.Fprintf(out, "export type FilterOp<V> =")
+	if len(ops) == 0 {
+		fmt.Fprintf(out, " never")
+	}
+	for _, op := range ops {
+		fmt.Fprintf(out, "\n  | { op: %q; v: %s }", op, tsOperatorValue(op))
+	}
+	fmt.Fprintf(out, ";\n\n")
+
+	fmt.Fprintf(out, "export type FieldFilter<V> = V | FilterOp<V>;\n\n")
+	fmt.Fprintf(out, "export type Filter<F> = F & { $and?: Filter<F>[]; $or?: Filter<F>[]; $not?: Filter<F> };\n\n")
+
+	fmt.Fprintf(out, "export interface ListParams {\n")
+	for _, param := range listParameters() {
+
+		name := param["name"].(string)
+
+		// passed as method arguments
+		if name == "filter" || name == "q" || name == "args" {
+			continue
+		}
+
+		paramType := tsType(param["schema"].(HM))
+		if name == "fields" {
+			paramType = "string[]"
+		}
+
+		fmt.Fprintf(out, "  %s?: %s;\n", tsProp(name), paramType)
+	}
+	fmt.Fprintf(out, "}\n\n")
+
+	out.WriteString(tsClientRuntime)
+
+	for _, res := range resources {
+		writeTsResource(out, res)
+	}
+
+	return out.String()
+}
+
+func writeTsResource(out *strings.Builder, res ResourceDoc) {
+
+	name := res.Name
+	idType := "string | number"
+
+	fmt.Fprintf(out, "\nexport interface %s {\n", name)
+	for _, field := range res.Fields {
+
+		if field.Column == res.PrimaryKey {
+			idType = tsType(field.Schema)
+		}
+
+		if !field.Outable {
+			continue
+		}
+
+		// fields hidden from some users are not always present
+		optional := ""
+		if field.AdminOnly || field.ReadRole != "" {
+			optional = "?"
+		}
+
+		fmt.Fprintf(out, "  %s%s: %s;\n", tsProp(field.Name), optional, tsType(field.Schema))
+	}
+	fmt.Fprintf(out, "}\n\n")
+
+	fmt.Fprintf(out, "export interface %sCreate {\n", name)
+	for _, field := range res.Fields {
+
+		if !field.Fillable {
+			continue
+		}
+
+		optional := "?"
+		for _, rule := range parseValidateTag(field.Validate) {
+			if rule.Name == "required" {
+				optional = ""
+			}
+		}
+
+		fmt.Fprintf(out, "  %s%s: %s;\n", tsProp(field.FillName), optional, tsType(field.Schema))
+	}
+	fmt.Fprintf(out, "}\n\n")
+
+	fmt.Fprintf(out, "export type %sUpdate = Partial<%sCreate>;\n\n", name, name)
+
+	fmt.Fprintf(out, "export type %sFilter = Filter<{\n", name)
+	for _, field := range res.Fields {
+		if field.Filterable {
+			fmt.Fprintf(out, "  %s?: FieldFilter<%s>;\n", tsProp(field.FillName), tsType(field.Schema))
+		}
+	}
+	for _, rel := range res.RelationFilters {
+		fmt.Fprintf(out, "  %s?: unknown;\n", tsProp(rel))
+	}
+	fmt.Fprintf(out, "}>;\n")
+
+	path, pathParams := tsPath(res.Path)
+
+	fmt.Fprintf(out, "\nexport class %sResource {\n", name)
+
+	if len(pathParams) > 0 {
+		props := []string{}
+		for _, it := range pathParams {
+			props = append(props, fmt.Sprintf("%s: string | number", tsProp(it)))
+		}
+		fmt.Fprintf(out, "  constructor(readonly api: SimpleApiClient, readonly params: { %s }) {}\n", strings.Join(props, "; "))
+	} else {
+		fmt.Fprintf(out, "  constructor(readonly api: SimpleApiClient) {}\n")
+	}
+
+	fmt.Fprintf(out, "\n  get path(): string {\n    return %s;\n  }\n", path)
+
+	if !res.Disabled["list"] {
+		fmt.Fprintf(out, "\n  list(filter?: %sFilter, params: ListParams = {}): Promise<ListResponse<%s>> {\n", name, name)
+		fmt.Fprintf(out, "    return this.api.request(\"GET\", this.path, { ...params, filter });\n  }\n")
+
+		fmt.Fprintf(out, "\n  query(template: string, args: Record<string, unknown> = {}, params: ListParams = {}): Promise<ListResponse<%s>> {\n", name)
+		fmt.Fprintf(out, "    return this.api.request(\"GET\", this.path, { ...params, q: template, args });\n  }\n")
+
+		for _, tpl := range res.QueryTemplates {
+
+			props := []string{}
+			for _, arg := range tpl.RequiredArgs {
+				props = append(props, fmt.Sprintf("%s: unknown", tsProp(arg)))
+			}
+			props = append(props, "[arg: string]: unknown")
+
+			fmt.Fprintf(out, "\n  query%s(args: { %s }, params: ListParams = {}): Promise<ListResponse<%s>> {\n", tsPascal(tpl.Name), strings.Join(props, "; "), name)
+			fmt.Fprintf(out, "    return this.query(%q, args, params);\n  }\n", tpl.Name)
+		}
+	}
+
+	if !res.Disabled["get"] {
+		fmt.Fprintf(out, "\n  async get(id: %s, fields?: string[]): Promise<%s> {\n", idType, name)
+		fmt.Fprintf(out, "    const resp = await this.api.request<{ item: %s }>(\"GET\", `${this.path}/${encodeURIComponent(String(id))}`, { fields });\n", name)
+		fmt.Fprintf(out, "    return resp.item;\n  }\n")
+	}
+
+	if !res.Disabled["create"] {
+		fmt.Fprintf(out, "\n  async create(data: %sCreate): Promise<%s> {\n", name, name)
+		fmt.Fprintf(out, "    const resp = await this.api.request<{ object: %s }>(\"POST\", this.path, undefined, data);\n", name)
+		fmt.Fprintf(out, "    return resp.object;\n  }\n")
+	}
+
+	if !res.Disabled["update"] {
+		fmt.Fprintf(out, "\n  async update(id: %s, patch: %sUpdate): Promise<%s> {\n", idType, name, name)
+		fmt.Fprintf(out, "    const resp = await this.api.request<{ item: %s }>(\"PATCH\", `${this.path}/${encodeURIComponent(String(id))}`, undefined, patch);\n", name)
+		fmt.Fprintf(out, "    return resp.item;\n  }\n")
+	}
+
+	if !res.Disabled["delete"] {
+		if res.Features["soft_delete"] {
+			fmt.Fprintf(out, "\n  delete(id: %s, purge = false): Promise<Record<string, unknown>> {\n", idType)
+			fmt.Fprintf(out, "    return this.api.request(\"DELETE\", `${this.path}/${encodeURIComponent(String(id))}`, { purge: purge || undefined });\n  }\n")
+		} else {
+			fmt.Fprintf(out, "\n  delete(id: %s): Promise<Record<string, unknown>> {\n", idType)
+			fmt.Fprintf(out, "    return this.api.request(\"DELETE\", `${this.path}/${encodeURIComponent(String(id))}`);\n  }\n")
+		}
+	}
+
+	fmt.Fprintf(out, "}\n")
+}
+
+// TypeScriptClient renders a typescript client of every crud generated within the group
+func (g *CrudGroup[T]) TypeScriptClient() string {
+	return TypeScriptClient(g.ResourceDocs())
+}
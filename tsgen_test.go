@@ -0,0 +1,51 @@
+package simpleapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTypeScriptClient(t *testing.T) {
+
+	source := mockCrudGroup(ServeMuxRouter(http.NewServeMux())).TypeScriptClient()
+
+	expected := []string{
+		"export interface MockEvent {",
+		"export interface MockAccountCreate {\n",
+		"  email: string;",
+		"export type MockEventFilter = Filter<{",
+		`| { op: "in"; v: V[] }`,
+		"export class MockEventResource {",
+		"return `/events`;",
+	}
+
+	for _, it := range expected {
+		if !strings.Contains(source, it) {
+			t.Errorf("generated client has no `%s`", it)
+		}
+	}
+
+	accountClient := tsBlock(source, "export class MockAccountResource")
+	if strings.Contains(accountClient, "delete(") {
+		t.Errorf("disabled delete endpoint has a client method")
+	}
+
+	profile := tsBlock(source, "export interface MockProfile {")
+	if strings.Contains(profile, "password") || !strings.Contains(profile, "handle: string;") {
+		t.Errorf("not outable field is in output type: %s", profile)
+	}
+
+	profileCreate := tsBlock(source, "export interface MockProfileCreate {")
+	if strings.Contains(profileCreate, "computed") || !strings.Contains(profileCreate, "password") || !strings.Contains(profileCreate, "about") {
+		t.Errorf("unexpected fillable fields in input type: %s", profileCreate)
+	}
+}
+
+// generated declaration starting with prefix, up to its closing brace
+func tsBlock(source string, prefix string) string {
+
+	block := source[strings.Index(source, prefix):]
+
+	return block[:strings.Index(block, "\n}")]
+}
@@ -3,25 +3,13 @@ package simpleapi
 import (
 	"log"
 	"reflect"
-	"strconv"
 	"strings"
 	"time"
 )
 
-type FieldValidation int
-
-const (
-	Unique FieldValidation = iota
-	Email
-	Required
-)
-
-type ValidationRuleSet struct {
-	RequiredFields map[string]FieldValidation
-}
-
 type ApiTags struct {
 	Validate *string
+	Rules    []FieldRule
 
 	TableColumnName string
 
@@ -32,6 +20,10 @@ type ApiTags struct {
 	WriteRole uint64
 	ReadRole  uint64
 
+	// named roles, resolved through role hierarchy
+	WriteRoleName string
+	ReadRoleName  string
+
 	// do not process it
 	DeclError bool
 
@@ -42,12 +34,32 @@ type ApiTags struct {
 	UserIdFlag bool // indicates that this field is substitued with authenticated user id on filter
 	AdminOnly  bool
 	Softdelete bool
+	DeletedBy  bool // stamped with authorized user id on soft removal
+	CreatedBy  bool // stamped with authorized user id on create
+	UpdatedBy  bool // stamped with authorized user id on create and update
+	Version    bool // optimistic locking counter, managed by server only
 
 	FillName *string
 	// outable name ?
 	Name *string
 }
 
+// checks if field value could be exposed or filtered with given request permissions
+func (f ApiTags) Readable(req RequestData) bool {
+
+	if f.AdminOnly && !req.IsAdmin {
+		return false
+	}
+
+	return roleAllows(f.ReadRole, f.ReadRoleName, req)
+}
+
+// checks if field could be filled from dto with given request permissions
+func (f ApiTags) Writable(req RequestData) bool {
+
+	return roleAllows(f.WriteRole, f.WriteRoleName, req)
+}
+
 type OnUpdateExecutor[T any] func(prev T, cur T)
 
 type UserReferenceInfo struct {
@@ -76,7 +88,10 @@ type FieldsMapping struct {
 	Filterable map[string]bool
 
 	UserReferenceField UserReferenceInfo
-	SoftDeleteField    UserReferenceInfo
+	SoftDeleteField    SoftDeleteInfo
+	VersionField       UserReferenceInfo
+	CreatedByField     UserReferenceInfo
+	UpdatedByField     UserReferenceInfo
 }
 
 // source : https://stackoverflow.com/questions/56616196/how-to-convert-camel-case-string-to-snake-case
@@ -269,16 +284,17 @@ func GetFieldTags[CtxType any, T any](obj T) (objMapp FieldsMapping) {
 		validate, hasValidate := fieldData.Tag.Lookup("validate")
 		if hasValidate {
 			result.Validate = &validate
+			result.Rules = parseValidateTag(validate)
 		}
 
 		role, hasRole := fieldData.Tag.Lookup("role")
 		if hasRole {
 			roles := strings.Split(role, ",")
 
-			result.WriteRole, _ = strconv.ParseUint(strings.TrimSpace(roles[0]), 10, 64)
+			result.WriteRole, result.WriteRoleName = parseRoleDecl(roles[0])
 
 			if len(roles) > 1 {
-				result.ReadRole, _ = strconv.ParseUint(strings.TrimSpace(roles[1]), 10, 64)
+				result.ReadRole, result.ReadRoleName = parseRoleDecl(roles[1])
 			}
 		}
 
@@ -300,6 +316,10 @@ func GetFieldTags[CtxType any, T any](obj T) (objMapp FieldsMapping) {
 		_, result.UserIdFlag = flagsMap["userid"]
 		_, result.AdminOnly = flagsMap["adminonly"]
 		_, result.Softdelete = flagsMap["softdelete"]
+		_, result.Version = flagsMap["version"]
+		_, result.DeletedBy = flagsMap["deletedby"]
+		_, result.CreatedBy = flagsMap["createdby"]
+		_, result.UpdatedBy = flagsMap["updatedby"]
 
 		if result.UserIdFlag {
 			objMapp.UserReferenceField = UserReferenceInfo{
@@ -311,12 +331,61 @@ func GetFieldTags[CtxType any, T any](obj T) (objMapp FieldsMapping) {
 		}
 
 		if result.Softdelete {
-			objMapp.SoftDeleteField = UserReferenceInfo{
+			objMapp.SoftDeleteField.UserReferenceInfo = UserReferenceInfo{
 				Has:             true,
 				DeclName:        declaredName,
 				TableColumnName: defName,
 				FillName:        fillName,
 			}
+			objMapp.SoftDeleteField.Kind = softDeleteKindOf(ftype)
+		}
+
+		if result.DeletedBy {
+			objMapp.SoftDeleteField.DeletedBy = UserReferenceInfo{
+				Has:             true,
+				DeclName:        declaredName,
+				TableColumnName: defName,
+				FillName:        fillName,
+			}
+
+			// stamped by server only
+			result.Fillable = false
+		}
+
+		if result.CreatedBy {
+			objMapp.CreatedByField = UserReferenceInfo{
+				Has:             true,
+				DeclName:        declaredName,
+				TableColumnName: defName,
+				FillName:        fillName,
+			}
+
+			// stamped by server only
+			result.Fillable = false
+		}
+
+		if result.UpdatedBy {
+			objMapp.UpdatedByField = UserReferenceInfo{
+				Has:             true,
+				DeclName:        declaredName,
+				TableColumnName: defName,
+				FillName:        fillName,
+			}
+
+			// stamped by server only
+			result.Fillable = false
+		}
+
+		if result.Version {
+			objMapp.VersionField = UserReferenceInfo{
+				Has:             true,
+				DeclName:        declaredName,
+				TableColumnName: defName,
+				FillName:        fillName,
+			}
+
+			// version is incremented on each save, never filled from client
+			result.Fillable = false
 		}
 
 		if !result.Internal && result.Fillable {
@@ -336,6 +405,29 @@ func GetFieldTags[CtxType any, T any](obj T) (objMapp FieldsMapping) {
 	return objMapp
 }
 
+// finds declared struct field name by a table column name
+func (m FieldsMapping) DeclaredByColumn(column string) (string, bool) {
+
+	for declName, it := range m.Fields {
+		if it.TableColumnName == column {
+			return declName, true
+		}
+	}
+
+	return "", false
+}
+
+// finds field info by a table column name
+func (m FieldsMapping) FieldByColumn(column string) (ApiTags, bool) {
+
+	declName, ok := m.DeclaredByColumn(column)
+	if !ok {
+		return ApiTags{}, false
+	}
+
+	return m.Fields[declName], true
+}
+
 func GetObjectType(obj any) string {
 	tobj := reflect.Indirect(reflect.ValueOf(obj)).Type()
 	return tobj.PkgPath() + "." + tobj.Name()
@@ -360,34 +452,54 @@ func (m FieldsMapping) ToDto(obj any, req RequestData) map[string]any {
 
 			fieldInfo := m.Fields[fieldName]
 
-			ivalue := reflected.FieldByName(fieldName).Interface()
-
-			if fieldInfo.AdminOnly && !req.IsAdmin {
+			if !fieldInfo.Readable(req) || !req.fieldSelected(*fieldInfo.Name) {
 				return
 			}
 
-			var val any = ivalue
+			ivalue := reflected.FieldByName(fieldName).Interface()
+
+			val := dtoValue(fieldInfo, ivalue)
+
+			result[*fieldInfo.Name] = val
+		}()
+	}
+
+	return result
+}
 
-			if fieldInfo.TypeKind == reflect.Struct {
+// converts field value into a dto representation, times are exposed as unix timestamps
+func dtoValue(fieldInfo ApiTags, ivalue any) any {
 
-				if fieldInfo.Typ == "time/Time" {
+	var val any = ivalue
 
-					switch ivalueTyped := ivalue.(type) {
-					case time.Time:
-						{
-							val = ivalueTyped.Unix()
-						}
-					}
+	if fieldInfo.TypeKind == reflect.Struct {
 
-				}
+		if fieldInfo.Typ == "time/Time" {
 
+			switch ivalueTyped := ivalue.(type) {
+			case time.Time:
+				{
+					val = ivalueTyped.Unix()
+				}
 			}
 
-			result[*fieldInfo.Name] = val
-		}()
+		}
+
 	}
 
-	return result
+	// nullable time, eg soft removal time
+	if fieldInfo.TypeKind == reflect.Pointer {
+		switch ivalueTyped := ivalue.(type) {
+		case *time.Time:
+			if ivalueTyped == nil {
+				val = nil
+			} else {
+				val = ivalueTyped.Unix()
+			}
+		}
+	}
+
+	return val
 }
 
 type FillFromDtoOptions struct {
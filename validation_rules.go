@@ -0,0 +1,354 @@
+package simpleapi
+
+import (
+	"fmt"
+	"log"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// single rule declared in a `validate` tag, eg `min=3`
+type FieldRule struct {
+	Name string
+	Arg  string
+}
+
+// context passed to every validation rule
+type ValidationContext struct {
+	Db *gorm.DB
+
+	TableName     string
+	PrimaryColumn string
+	// nil when entity is not stored yet
+	PrimaryValue any
+
+	Field ApiTags
+	Req   RequestData
+}
+
+// returns nil when value is valid
+type ValidationRule func(vctx ValidationContext, value reflect.Value, arg string) error
+
+// fill name -> error message
+type ValidationErrors map[string]string
+
+func (v ValidationErrors) Error() string {
+	parts := []string{}
+
+	for fname, msg := range v {
+		parts = append(parts, fmt.Sprintf("%s: %s", fname, msg))
+	}
+
+	return fmt.Sprintf("validation failed: %s", strings.Join(parts, "; "))
+}
+
+func (v ValidationErrors) RespErr() *RespErr {
+	return NewRespErr(422, HM{
+		"msg":    "validation failed",
+		"fields": map[string]string(v),
+	})
+}
+
+var (
+	ErrValueRequired = fmt.Errorf("value is required")
+	ErrValueNotEmail = fmt.Errorf("value is not a valid email")
+	ErrValueNotOneOf = fmt.Errorf("value is not allowed")
+	ErrValueNotMatch = fmt.Errorf("value has wrong format")
+	ErrValueNotUniq  = fmt.Errorf("value is already used")
+)
+
+var validationRules = map[string]ValidationRule{
+	"required": func(vctx ValidationContext, value reflect.Value, arg string) error {
+		if value.IsZero() {
+			return ErrValueRequired
+		}
+		return nil
+	},
+	"email": func(vctx ValidationContext, value reflect.Value, arg string) error {
+		if value.IsZero() {
+			return nil
+		}
+
+		str := fmt.Sprintf("%v", value.Interface())
+
+		addr, err := mail.ParseAddress(str)
+		if err != nil || addr.Address != str {
+			return ErrValueNotEmail
+		}
+		return nil
+	},
+	"min": func(vctx ValidationContext, value reflect.Value, arg string) error {
+		return checkBound(value, arg, false)
+	},
+	"max": func(vctx ValidationContext, value reflect.Value, arg string) error {
+		return checkBound(value, arg, true)
+	},
+	"regex": func(vctx ValidationContext, value reflect.Value, arg string) error {
+		if value.IsZero() {
+			return nil
+		}
+
+		re, err := compiledRegex(arg)
+		if err != nil {
+			return err
+		}
+
+		if !re.MatchString(fmt.Sprintf("%v", value.Interface())) {
+			return ErrValueNotMatch
+		}
+		return nil
+	},
+	"oneof": func(vctx ValidationContext, value reflect.Value, arg string) error {
+		if value.IsZero() {
+			return nil
+		}
+
+		str := fmt.Sprintf("%v", value.Interface())
+
+		for _, it := range strings.Fields(arg) {
+			if it == str {
+				return nil
+			}
+		}
+
+		return ErrValueNotOneOf
+	},
+	// checked before the object is stored, so concurrent requests could both pass it.
+	// a unique index on the column is still required, this only gives a readable error
+	"unique": func(vctx ValidationContext, value reflect.Value, arg string) error {
+		if value.IsZero() || vctx.Db == nil {
+			return nil
+		}
+
+		cnt := int64(0)
+
+		q := vctx.Db.Table(vctx.TableName).Where(fmt.Sprintf("%s = ?", vctx.Field.TableColumnName), value.Interface())
+
+		if vctx.PrimaryValue != nil {
+			q = q.Where(fmt.Sprintf("%s != ?", vctx.PrimaryColumn), vctx.PrimaryValue)
+		}
+
+		err := q.Count(&cnt).Error
+		if err != nil {
+			return err
+		}
+
+		if cnt > 0 {
+			return ErrValueNotUniq
+		}
+		return nil
+	},
+}
+
+// allows to add custom named rules usable from `validate` tags.
+// existing rules with the same name are overriden
+func RegisterValidationRule(name string, rule ValidationRule) {
+	validationRules[name] = rule
+}
+
+var regexCache = sync.Map{}
+
+func compiledRegex(expr string) (*regexp.Regexp, error) {
+
+	cached, ok := regexCache.Load(expr)
+	if ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("bad regex in validate tag: %s", err.Error())
+	}
+
+	regexCache.Store(expr, re)
+
+	return re, nil
+}
+
+// min/max check length of strings, slices and maps and value of numbers
+func checkBound(value reflect.Value, arg string, isMax bool) error {
+
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return fmt.Errorf("bad bound `%s` in validate tag", arg)
+	}
+
+	var actual float64
+	isLen := false
+
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		actual = float64(value.Len())
+		isLen = true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	default:
+		return fmt.Errorf("min/max is not supported for %s", value.Kind())
+	}
+
+	if isMax && actual > bound {
+		if isLen {
+			return fmt.Errorf("length should be at most %s", arg)
+		}
+		return fmt.Errorf("value should be at most %s", arg)
+	}
+
+	if !isMax && actual < bound {
+		if isLen {
+			return fmt.Errorf("length should be at least %s", arg)
+		}
+		return fmt.Errorf("value should be at least %s", arg)
+	}
+
+	return nil
+}
+
+// parses `required,min=3,regex=^[a-z,]+$`
+// regex consumes the rest of the tag, so it may contain commas but should be declared last
+func parseValidateTag(tag string) []FieldRule {
+
+	result := []FieldRule{}
+
+	rest := strings.TrimSpace(tag)
+
+	for rest != "" {
+
+		var part string
+
+		if strings.HasPrefix(rest, "regex=") {
+			part = rest
+			rest = ""
+		} else {
+			idx := strings.Index(rest, ",")
+			if idx < 0 {
+				part = rest
+				rest = ""
+			} else {
+				part = rest[:idx]
+				rest = strings.TrimSpace(rest[idx+1:])
+			}
+		}
+
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, arg, _ := strings.Cut(part, "=")
+
+		result = append(result, FieldRule{
+			Name: strings.TrimSpace(name),
+			Arg:  arg,
+		})
+	}
+
+	return result
+}
+
+// validates declared `validate` rules of all fields of an object.
+// returns empty map if object is valid
+func (c AppContext[T]) ValidateEntity(modelTypeData FieldsMapping, obj any, target ValidationContext, req RequestData) ValidationErrors {
+
+	result := ValidationErrors{}
+
+	reflected := reflect.Indirect(reflect.ValueOf(obj))
+
+	for declName, fieldInfo := range modelTypeData.Fields {
+
+		rules := fieldInfo.Rules
+
+		if len(rules) == 0 || fieldInfo.DeclError {
+			continue
+		}
+
+		errKey := declName
+		if fieldInfo.FillName != nil {
+			errKey = *fieldInfo.FillName
+		}
+
+		fieldValue := reflected.FieldByName(declName)
+
+		// nil pointer is an omitted optional field, only presence is checked.
+		// other rules are applied to the pointed value
+		omitted := false
+
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				omitted = true
+			} else {
+				fieldValue = fieldValue.Elem()
+			}
+		}
+
+		vctx := target
+		vctx.Field = fieldInfo
+		vctx.Req = req
+
+		for _, rule := range rules {
+
+			if omitted && rule.Name != "required" {
+				continue
+			}
+
+			ruleH, ok := validationRules[rule.Name]
+			if !ok {
+				log.Printf("unknown validation rule `%s` on field %s.%s, skipped", rule.Name, modelTypeData.TypeName, declName)
+				continue
+			}
+
+			err := func() (err error) {
+
+				defer func() {
+					rec := recover()
+					if rec != nil {
+						err = fmt.Errorf("unable to validate: %v", rec)
+					}
+				}()
+
+				return ruleH(vctx, fieldValue, rule.Arg)
+			}()
+
+			if err != nil {
+				req.log_format(" [%s] failed `%s` validation: %s", declName, rule.Name, err.Error())
+
+				result[errKey] = err.Error()
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// validates an entity of crud type, unique rules are checked against crud table
+func (result *CrudConfig[T, CtxType]) ValidateEntity(appctx *AppContext[CtxType], obj *T, req RequestData) ValidationErrors {
+
+	target := ValidationContext{
+		Db:            appctx.Db.Raw(),
+		TableName:     result.tableName,
+		PrimaryColumn: result.primaryIdDbName,
+	}
+
+	for declName, fieldInfo := range result.TypeDataModel.Fields {
+		if fieldInfo.TableColumnName == result.primaryIdDbName {
+
+			pkValue := reflect.ValueOf(obj).Elem().FieldByName(declName)
+			if pkValue.IsValid() && !pkValue.IsZero() {
+				target.PrimaryValue = pkValue.Interface()
+			}
+			break
+		}
+	}
+
+	return appctx.ValidateEntity(result.TypeDataModel, obj, target, req)
+}
@@ -0,0 +1,116 @@
+package simpleapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValidateTag(t *testing.T) {
+
+	rules := parseValidateTag("required, min=3,regex=^[a-z,]+$")
+
+	expected := []FieldRule{
+		{Name: "required"},
+		{Name: "min", Arg: "3"},
+		{Name: "regex", Arg: "^[a-z,]+$"},
+	}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("unexpected rules parsed: %#+v", rules)
+	}
+}
+
+func TestValidateEntity(t *testing.T) {
+
+	fields := GetFieldTags[MockAppContext, MockAccount](MockAccount{})
+	appCtx := AppContext[MockAppContext]{
+		Data: &MockAppContext{},
+	}
+
+	valid := MockAccount{
+		Email:    "test@test.com",
+		Nickname: "tester",
+		Age:      20,
+		Plan:     "pro",
+	}
+
+	errs := appCtx.ValidateEntity(fields, &valid, ValidationContext{}, RequestData{})
+	if len(errs) != 0 {
+		t.Errorf("valid object has errors: %v", errs)
+	}
+
+	invalid := MockAccount{
+		Nickname: "Te",
+		Age:      10,
+		Plan:     "gold",
+	}
+
+	errs = appCtx.ValidateEntity(fields, &invalid, ValidationContext{}, RequestData{})
+
+	for _, fname := range []string{"email", "nick", "age", "plan"} {
+		if _, ok := errs[fname]; !ok {
+			t.Errorf("expected validation error for `%s`, got %v", fname, errs)
+		}
+	}
+}
+
+func TestCustomValidationRule(t *testing.T) {
+
+	RegisterValidationRule("even", func(vctx ValidationContext, value reflect.Value, arg string) error {
+		if value.Int()%2 != 0 {
+			return ErrValueNotMatch
+		}
+		return nil
+	})
+
+	type evenHolder struct {
+		Val int `validate:"even"`
+	}
+
+	fields := GetFieldTags[MockAppContext](evenHolder{})
+	appCtx := AppContext[MockAppContext]{}
+
+	errs := appCtx.ValidateEntity(fields, &evenHolder{Val: 3}, ValidationContext{}, RequestData{})
+	if errs["val"] != ErrValueNotMatch.Error() {
+		t.Errorf("custom rule was not applied: %v", errs)
+	}
+}
+
+func TestValidatePointerFields(t *testing.T) {
+
+	type profile struct {
+		Email *string `validate:"email"`
+		Nick  *string `validate:"required,min=3,regex=^[a-z]+$"`
+		Age   *int    `validate:"min=18"`
+		Plan  string  `validate:"oneof=free pro"`
+	}
+
+	fields := GetFieldTags[MockAppContext](profile{})
+	appCtx := AppContext[MockAppContext]{}
+
+	email := "test@test.com"
+	nick := "tester"
+	age := 20
+
+	errs := appCtx.ValidateEntity(fields, &profile{Email: &email, Nick: &nick, Age: &age}, ValidationContext{}, RequestData{})
+	if len(errs) != 0 {
+		t.Errorf("valid pointer values have errors: %v", errs)
+	}
+
+	// omitted optional fields and empty oneof are valid, required is not
+	errs = appCtx.ValidateEntity(fields, &profile{}, ValidationContext{}, RequestData{})
+	if len(errs) != 1 || errs["nick"] != ErrValueRequired.Error() {
+		t.Errorf("only required pointer field should fail: %v", errs)
+	}
+
+	badEmail := "nope"
+	shortNick := "ab"
+	young := 10
+
+	errs = appCtx.ValidateEntity(fields, &profile{Email: &badEmail, Nick: &shortNick, Age: &young}, ValidationContext{}, RequestData{})
+	for _, fname := range []string{"email", "nick", "age"} {
+		if _, ok := errs[fname]; !ok {
+			t.Errorf("expected validation error for `%s`, got %v", fname, errs)
+		}
+	}
+}
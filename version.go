@@ -0,0 +1,110 @@
+package simpleapi
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+var ErrVersionConflict = fmt.Errorf("object was modified by another request")
+
+func versionConflictResp() *RespErr {
+	return NewRespErr(412, HM{
+		"msg": "object was modified, reload it and try again",
+	})
+}
+
+// increments version field of an object, returns previous value
+func incrementVersion(obj any, declName string) (prev any, err error) {
+
+	field := reflect.Indirect(reflect.ValueOf(obj)).FieldByName(declName)
+
+	prev = field.Interface()
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(field.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(field.Uint() + 1)
+	default:
+		err = fmt.Errorf("version field should be an integer, got %s", field.Kind())
+	}
+
+	return
+}
+
+// entity tag of an object: id and version if model is versioned, content hash otherwise.
+// only versioned tags guard writes: the version is checked by the UPDATE or DELETE itself.
+// content hash is compared with `If-Match` before saving without any condition,
+// so two concurrent writers holding the same tag could both succeed; such tags are advisory,
+// declare a `simpleapi:"version"` field when lost updates matter
+func (result *CrudConfig[T, CtxType]) ETag(obj T) string {
+
+	reflected := reflect.ValueOf(obj)
+
+	versionField := result.TypeDataModel.VersionField
+
+	if versionField.Has {
+
+		var id any
+
+		pkDecl, ok := result.TypeDataModel.DeclaredByColumn(result.primaryIdDbName)
+		if ok {
+			id = reflected.FieldByName(pkDecl).Interface()
+		}
+
+		return fmt.Sprintf(`"%v-%v"`, id, reflected.FieldByName(versionField.DeclName).Interface())
+	}
+
+	data, _ := json.Marshal(obj)
+	sum := sha1.Sum(data)
+
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:]))
+}
+
+// checks `If-Match` header value against current entity tag.
+// empty header always matches, for non versioned models the check is advisory, see ETag
+func etagMatches(ifMatch string, etag string) bool {
+
+	ifMatch = strings.TrimSpace(ifMatch)
+
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+
+	for _, it := range strings.Split(ifMatch, ",") {
+
+		it = strings.TrimPrefix(strings.TrimSpace(it), "W/")
+
+		if it == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// client could pass a version it has seen within update body instead of `If-Match`
+func (result *CrudConfig[T, CtxType]) versionMatches(obj T, parsed gjson.Result) bool {
+
+	versionField := result.TypeDataModel.VersionField
+
+	if !versionField.Has {
+		return true
+	}
+
+	passed := parsed.Get(versionField.FillName)
+
+	if !passed.Exists() {
+		return true
+	}
+
+	current := reflect.ValueOf(obj).FieldByName(versionField.DeclName).Interface()
+
+	return passed.String() == fmt.Sprintf("%v", current)
+}
@@ -0,0 +1,101 @@
+package simpleapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tidwall/gjson"
+	"gorm.io/gorm"
+)
+
+func TestVersionField(t *testing.T) {
+
+	fields := GetFieldTags[MockAppContext, MockDocument](MockDocument{})
+
+	if !fields.VersionField.Has || fields.Fields["Version"].Fillable {
+		t.Fatalf("version field should be detected and not fillable: %#+v", fields.VersionField)
+	}
+
+	crud := &CrudConfig[MockDocument, MockAppContext]{
+		TypeDataModel:   fields,
+		primaryIdDbName: "id",
+	}
+
+	doc := MockDocument{Id: 5, Version: 2}
+
+	etag := crud.ETag(doc)
+	if etag != `"5-2"` {
+		t.Errorf("unexpected etag: %s", etag)
+	}
+
+	if !etagMatches(`W/"5-2", "5-3"`, etag) || etagMatches(`"5-1"`, etag) || !etagMatches("", etag) {
+		t.Errorf("if-match is not checked properly")
+	}
+
+	if crud.versionMatches(doc, gjson.Parse(`{"version":1}`)) || !crud.versionMatches(doc, gjson.Parse(`{"version":2}`)) {
+		t.Errorf("passed version is not checked properly")
+	}
+
+	prev, err := incrementVersion(&doc, "Version")
+	if err != nil || prev != uint32(2) || doc.Version != 3 {
+		t.Errorf("version was not incremented: %v %v %d", err, prev, doc.Version)
+	}
+}
+
+// concurrent save commits between loading the object and removing it
+func bumpVersionOnDelete[T any](table string) func(crudContext CrudContext[T, MockAppContext], obj *T) error {
+	return func(crudContext CrudContext[T, MockAppContext], obj *T) error {
+		return crudContext.App.Db.Raw().Table(table).Where("id = ?", 1).Update("version", gorm.Expr("version + 1")).Error
+	}
+}
+
+func TestStaleDelete(t *testing.T) {
+
+	group, mux := mockDbGroup(t, &MockDocument{}, &MockPage{})
+
+	router := ServeMuxRouter(mux)
+
+	documents := New(group, router.Group("/documents"), MockDocument{})
+	documents.Generate()
+
+	pages := New(group, router.Group("/pages"), MockPage{})
+	pages.Generate()
+
+	app := group.Ctx
+
+	app.Db.Create(&MockDocument{Body: "doc"})
+	app.Db.Create(&MockPage{Body: "page"})
+
+	documents.OnBeforeDelete(bumpVersionOnDelete[MockDocument]("mock_documents"))
+	pages.OnBeforeDelete(bumpVersionOnDelete[MockPage]("mock_pages"))
+
+	for _, path := range []string{"/documents/1", "/documents/1?purge=true", "/pages/1", "/pages/1?purge=true"} {
+
+		resp := mockRequest(t, mux, http.MethodDelete, path, "", "X-Admin: 1")
+		if resp.Code != 412 {
+			t.Errorf("%s: stale removal should be rejected: %d %v", path, resp.Code, resp.Body)
+		}
+	}
+
+	page := MockPage{}
+	app.Db.Raw().First(&page, 1)
+
+	if countRows[MockDocument](t, app) != 1 || page.Removed || page.Version != 0 {
+		t.Errorf("rejected removals should be rolled back: %+v", page)
+	}
+
+	documents.OnBeforeDelete(nil)
+	pages.OnBeforeDelete(nil)
+
+	resp := mockRequest(t, mux, http.MethodDelete, "/pages/1", "")
+	app.Db.Raw().First(&page, 1)
+
+	if resp.Code != 200 || !page.Removed || page.Version != 1 {
+		t.Errorf("soft removal should bump version: %d %+v", resp.Code, page)
+	}
+
+	resp = mockRequest(t, mux, http.MethodDelete, "/documents/1", "")
+	if resp.Code != 200 || countRows[MockDocument](t, app) != 0 {
+		t.Errorf("removal failed: %d %v", resp.Code, resp.Body)
+	}
+}